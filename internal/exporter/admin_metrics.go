@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// adminOperation 一种定期清理操作对应的计数和计时 MBean
+type adminOperation struct {
+	count string
+	time  string
+}
+
+// adminOperations puppetlabs.puppetdb.admin 中的清理操作，键为 operation 标签值
+var adminOperations = map[string]adminOperation{
+	"node_expiration":      {"node-expirations", "node-expiration-time"},
+	"node_purge":           {"node-purges", "node-purge-time"},
+	"report_purge":         {"report-purges", "report-purge-time"},
+	"resource_event_purge": {"resource-event-purges", "resource-events-purge-time"},
+	"catalog_gc":           {"catalog-gcs", "catalog-gc-time"},
+	"package_gc":           {"package-gcs", "package-gc-time"},
+	"fact_path_gc":         {"fact-path-gcs", "fact-path-gc-time"},
+	"other_clean":          {"other-cleans", "other-clean-time"},
+}
+
+// AdminMetrics 定义 PuppetDB 定期清理（节点过期、清除和垃圾回收）的指标
+// 指标值为 PuppetDB 的累计快照，因此以常量指标的方式在收集时输出
+type AdminMetrics struct {
+	mu   sync.Mutex
+	data map[string]map[string]interface{}
+
+	operationsDesc *prometheus.Desc
+	cleaningDesc   *prometheus.Desc
+	duration       *DropwizardMetric
+}
+
+// NewAdminMetrics 创建清理操作指标实例
+func NewAdminMetrics(namespace string) *AdminMetrics {
+	return &AdminMetrics{
+		operationsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "admin", "operations_total"),
+			"Number of periodic cleanup operations run by PuppetDB, by operation.",
+			[]string{"operation"}, nil,
+		),
+		cleaningDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "admin", "cleaning"),
+			"Number of PuppetDB cleanup runs currently in progress.",
+			nil, nil,
+		),
+		duration: NewDropwizardMetric(DropwizardOpts{
+			Namespace: namespace,
+			Subsystem: "admin",
+			Name:      "operation_duration",
+			Unit:      "seconds",
+			Help:      "Duration of periodic cleanup operations in seconds, by operation.",
+		}, []string{"operation"}),
+	}
+}
+
+// Register 注册清理操作指标
+func (am *AdminMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(am)
+}
+
+// UpdateAdminMetrics 保存最新的 puppetlabs.puppetdb.admin MBean 数据，键为 MBean 的 name 属性
+func (am *AdminMetrics) UpdateAdminMetrics(data map[string]map[string]interface{}) {
+	durations := make([]DropwizardSample, 0, len(adminOperations))
+	for operation, mbeans := range adminOperations {
+		durations = append(durations, DropwizardSample{LabelValues: []string{operation}, Data: data[mbeans.time]})
+	}
+	am.duration.Update(durations)
+
+	am.mu.Lock()
+	am.data = data
+	am.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (am *AdminMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- am.operationsDesc
+	ch <- am.cleaningDesc
+	am.duration.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector
+func (am *AdminMetrics) Collect(ch chan<- prometheus.Metric) {
+	am.mu.Lock()
+	data := am.data
+	am.mu.Unlock()
+
+	for operation, mbeans := range adminOperations {
+		if count, ok := data[mbeans.count]["Count"]; ok {
+			ch <- prometheus.MustNewConstMetric(am.operationsDesc, prometheus.CounterValue, numberToFloat(count), operation)
+		}
+	}
+	if cleaning, ok := data["cleaning"]["Count"]; ok {
+		ch <- prometheus.MustNewConstMetric(am.cleaningDesc, prometheus.GaugeValue, numberToFloat(cleaning))
+	}
+
+	am.duration.Collect(ch)
+}
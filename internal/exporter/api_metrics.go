@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/camptocamp/prometheus-puppetdb-exporter/internal/puppetdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// APIMetrics 定义查询、状态和指标 API 各自的健康指标
+// 三个 API 可以配置不同的地址和认证，因此分别统计请求结果
+type APIMetrics struct {
+	mu      sync.Mutex
+	clients map[string]*puppetdb.PuppetDB
+
+	upDesc           *prometheus.Desc
+	requestsDesc     *prometheus.Desc
+	failuresDesc     *prometheus.Desc
+	lastSuccessDesc  *prometheus.Desc
+	lastDurationDesc *prometheus.Desc
+}
+
+// NewAPIMetrics 创建 API 健康指标实例
+func NewAPIMetrics(namespace string) *APIMetrics {
+	return &APIMetrics{
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "api", "up"),
+			"Whether the last request to the PuppetDB API succeeded (1=yes, 0=no).",
+			[]string{"api"}, nil,
+		),
+		requestsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "api", "requests_total"),
+			"Number of requests sent to the PuppetDB API.",
+			[]string{"api"}, nil,
+		),
+		failuresDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "api", "request_failures_total"),
+			"Number of requests to the PuppetDB API that failed to connect or returned a 4xx/5xx status.",
+			[]string{"api"}, nil,
+		),
+		lastSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "api", "last_success_timestamp_seconds"),
+			"Time of the last successful request to the PuppetDB API (UNIX epoch).",
+			[]string{"api"}, nil,
+		),
+		lastDurationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "api", "last_request_duration_seconds"),
+			"Duration of the last request to the PuppetDB API in seconds.",
+			[]string{"api"}, nil,
+		),
+	}
+}
+
+// Register 注册 API 健康指标
+func (am *APIMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(am)
+}
+
+// SetClients 设置需要统计的客户端，以 API 名称（query、status、metrics）为键
+func (am *APIMetrics) SetClients(clients map[string]*puppetdb.PuppetDB) {
+	am.mu.Lock()
+	am.clients = clients
+	am.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (am *APIMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- am.upDesc
+	ch <- am.requestsDesc
+	ch <- am.failuresDesc
+	ch <- am.lastSuccessDesc
+	ch <- am.lastDurationDesc
+}
+
+// Collect 实现 prometheus.Collector，尚未发送请求的 API 不输出
+func (am *APIMetrics) Collect(ch chan<- prometheus.Metric) {
+	am.mu.Lock()
+	clients := am.clients
+	am.mu.Unlock()
+
+	for api, client := range clients {
+		health := client.Health()
+		if health.Requests == 0 {
+			continue
+		}
+
+		up := 0.0
+		if health.Up {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(am.upDesc, prometheus.GaugeValue, up, api)
+		ch <- prometheus.MustNewConstMetric(am.requestsDesc, prometheus.CounterValue, health.Requests, api)
+		ch <- prometheus.MustNewConstMetric(am.failuresDesc, prometheus.CounterValue, health.Failures, api)
+		ch <- prometheus.MustNewConstMetric(am.lastDurationDesc, prometheus.GaugeValue, health.LastDuration.Seconds(), api)
+		if !health.LastSuccess.IsZero() {
+			ch <- prometheus.MustNewConstMetric(am.lastSuccessDesc, prometheus.GaugeValue, float64(health.LastSuccess.Unix()), api)
+		}
+	}
+}
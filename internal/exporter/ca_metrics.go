@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CAMetrics 定义 Puppet CA 证书相关的指标
+type CAMetrics struct {
+	certificates            *prometheus.GaugeVec
+	certificateExpiry       *prometheus.GaugeVec
+	certificateExpiresIn    *certificateExpiresIn
+	signedNeverReported     *prometheus.GaugeVec
+	revokedReporting        *prometheus.GaugeVec
+	signedNeverReportedNode *prometheus.GaugeVec
+	revokedReportingNode    *prometheus.GaugeVec
+}
+
+// certificateExpiresIn 在收集时根据证书的过期时间计算剩余时间，两次 CA 刷新之间剩余时间仍然递减
+type certificateExpiresIn struct {
+	mu       sync.Mutex
+	expiries map[string]time.Time
+	now      func() time.Time
+	desc     *prometheus.Desc
+}
+
+// Describe 实现 prometheus.Collector
+func (ce *certificateExpiresIn) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ce.desc
+}
+
+// Collect 实现 prometheus.Collector
+func (ce *certificateExpiresIn) Collect(ch chan<- prometheus.Metric) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	now := ce.now()
+	for host, expiry := range ce.expiries {
+		ch <- prometheus.MustNewConstMetric(ce.desc, prometheus.GaugeValue, expiry.Sub(now).Seconds(), host)
+	}
+}
+
+// CAPuppetDBNode 与 CA 证书对账所需的 PuppetDB 节点信息
+type CAPuppetDBNode struct {
+	Environment string
+	Active      bool
+	HasReport   bool
+}
+
+// NewCAMetrics 创建 Puppet CA 指标实例
+func NewCAMetrics(namespace string) *CAMetrics {
+	cm := &CAMetrics{}
+
+	cm.certificates = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_certificates",
+		Help:      "Number of Puppet CA certificates by state (requested/signed/revoked).",
+	}, []string{"state"})
+
+	cm.certificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_certificate_expiry_timestamp_seconds",
+		Help:      "Expiry time of the node's signed certificate (UNIX epoch).",
+	}, []string{"host"})
+
+	cm.certificateExpiresIn = &certificateExpiresIn{
+		now: time.Now,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "ca_certificate_expires_in_seconds"),
+			"Time left before the node's signed certificate expires in seconds.", []string{"host"}, nil),
+	}
+
+	cm.signedNeverReported = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_signed_never_reported",
+		Help:      "Number of signed certificates whose node never reported to PuppetDB.",
+	}, []string{})
+
+	cm.revokedReporting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_revoked_reporting",
+		Help:      "Number of revoked certificates whose node is still active in PuppetDB.",
+	}, []string{})
+
+	cm.signedNeverReportedNode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_certificate_never_reported",
+		Help:      "Signed certificate whose node never reported to PuppetDB (always 1).",
+	}, []string{"host"})
+
+	cm.revokedReportingNode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_certificate_revoked_reporting",
+		Help:      "Revoked certificate whose node is still active in PuppetDB (always 1).",
+	}, []string{"environment", "host"})
+
+	return cm
+}
+
+// Register 注册所有 Puppet CA 指标
+func (cm *CAMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(cm.certificates)
+	registerer.MustRegister(cm.certificateExpiry)
+	registerer.MustRegister(cm.certificateExpiresIn)
+	registerer.MustRegister(cm.signedNeverReported)
+	registerer.MustRegister(cm.revokedReporting)
+	registerer.MustRegister(cm.signedNeverReportedNode)
+	registerer.MustRegister(cm.revokedReportingNode)
+}
+
+// UpdateCertificates 更新证书状态计数和已签名证书的过期时间
+func (cm *CAMetrics) UpdateCertificates(counts map[string]int, expiries map[string]time.Time) {
+	cm.certificates.Reset()
+	cm.certificateExpiry.Reset()
+
+	for _, state := range []string{"requested", "signed", "revoked"} {
+		cm.certificates.With(prometheus.Labels{"state": state}).Set(float64(counts[state]))
+	}
+	for host, expiry := range expiries {
+		cm.certificateExpiry.With(prometheus.Labels{"host": host}).Set(float64(expiry.Unix()))
+	}
+
+	cm.certificateExpiresIn.mu.Lock()
+	cm.certificateExpiresIn.expiries = expiries
+	cm.certificateExpiresIn.mu.Unlock()
+}
+
+// UpdateCrossReference 对比已签名和已吊销的证书与 PuppetDB 中的节点
+func (cm *CAMetrics) UpdateCrossReference(signed []string, revoked []string, nodes map[string]CAPuppetDBNode) {
+	cm.signedNeverReportedNode.Reset()
+	cm.revokedReportingNode.Reset()
+
+	neverReported := 0
+	for _, host := range signed {
+		if node, ok := nodes[host]; !ok || !node.HasReport {
+			cm.signedNeverReportedNode.With(prometheus.Labels{"host": host}).Set(1)
+			neverReported++
+		}
+	}
+
+	revokedReporting := 0
+	for _, host := range revoked {
+		if node, ok := nodes[host]; ok && node.Active && node.HasReport {
+			cm.revokedReportingNode.With(prometheus.Labels{"environment": node.Environment, "host": host}).Set(1)
+			revokedReporting++
+		}
+	}
+
+	cm.signedNeverReported.WithLabelValues().Set(float64(neverReported))
+	cm.revokedReporting.WithLabelValues().Set(float64(revokedReporting))
+}
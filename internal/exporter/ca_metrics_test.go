@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateExpiresIn(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cm := NewCAMetrics("puppetdb")
+	cm.certificateExpiresIn.now = func() time.Time { return now }
+	registry := prometheus.NewRegistry()
+	cm.Register(registry)
+
+	cm.UpdateCertificates(map[string]int{"signed": 2}, map[string]time.Time{
+		"web1": now.Add(48 * time.Hour),
+		"web2": now.Add(-time.Hour),
+	})
+
+	expiresIn := func() map[string]float64 {
+		families, err := registry.Gather()
+		assert.NoError(t, err)
+		values := make(map[string]float64)
+		for _, family := range families {
+			if family.GetName() != "puppetdb_ca_certificate_expires_in_seconds" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				values[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+			}
+		}
+		return values
+	}
+
+	assert.Equal(t, map[string]float64{"web1": 48 * 3600, "web2": -3600}, expiresIn())
+
+	// 没有刷新 CA 时剩余时间按收集时间递减
+	now = now.Add(time.Hour)
+	assert.Equal(t, map[string]float64{"web1": 47 * 3600, "web2": -7200}, expiresIn())
+}
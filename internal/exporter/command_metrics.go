@@ -0,0 +1,138 @@
+package exporter
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CommandMBeanSample 单个命令版本的消息队列 MBean 数据
+type CommandMBeanSample struct {
+	Command string
+	Version string
+	Metric  string
+	Data    map[string]interface{}
+}
+
+// commandCounterMetrics 累计计数的命令指标
+var commandCounterMetrics = []string{"processed", "fatal", "retried", "discarded", "ignored"}
+
+// commandGaugeMetrics 当前值的命令指标
+var commandGaugeMetrics = []string{"awaiting-retry", "depth"}
+
+// commandTimerMetrics Dropwizard 计时器类型的命令指标
+var commandTimerMetrics = []string{"queue-time", "processing-time", "message-persistence-time"}
+
+// CommandMetrics 定义按命令和版本划分的消息队列指标
+// 指标值为 PuppetDB 的累计快照，因此以常量指标的方式在收集时输出
+type CommandMetrics struct {
+	mu           sync.Mutex
+	samples      []CommandMBeanSample
+	timerSamples map[string][]DropwizardSample
+	descs        map[string]*prometheus.Desc
+	timers       map[string]*DropwizardMetric
+}
+
+// NewCommandMetrics 创建命令指标实例
+func NewCommandMetrics(namespace string) *CommandMetrics {
+	cm := &CommandMetrics{
+		descs:  make(map[string]*prometheus.Desc),
+		timers: make(map[string]*DropwizardMetric),
+	}
+
+	labels := []string{"command", "version"}
+	for _, metric := range commandCounterMetrics {
+		cm.descs[metric] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mq", "command_"+strings.ReplaceAll(metric, "-", "_")+"_total"),
+			"Number of commands "+metric+" by PuppetDB, by command and version.",
+			labels, nil,
+		)
+	}
+	for _, metric := range commandGaugeMetrics {
+		cm.descs[metric] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mq", "command_"+strings.ReplaceAll(metric, "-", "_")),
+			"Current "+metric+" of the command queue, by command and version.",
+			labels, nil,
+		)
+	}
+	for _, metric := range commandTimerMetrics {
+		cm.timers[metric] = NewDropwizardMetric(DropwizardOpts{
+			Namespace: namespace,
+			Subsystem: "mq",
+			Name:      "command_" + strings.ReplaceAll(metric, "-", "_"),
+			Unit:      "seconds",
+			Help:      "Command " + metric + " in seconds, by command and version.",
+		}, labels)
+	}
+
+	return cm
+}
+
+// Register 注册命令指标
+func (cm *CommandMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(cm)
+}
+
+// UpdateCommandMetrics 保存最新的命令 MBean 数据，计时器数据先整理好再一起替换
+func (cm *CommandMetrics) UpdateCommandMetrics(samples []CommandMBeanSample) {
+	timerSamples := make(map[string][]DropwizardSample, len(cm.timers))
+	for _, sample := range samples {
+		if _, ok := cm.timers[sample.Metric]; ok {
+			timerSamples[sample.Metric] = append(timerSamples[sample.Metric], DropwizardSample{
+				LabelValues: []string{sample.Command, sample.Version},
+				Data:        sample.Data,
+			})
+		}
+	}
+
+	cm.mu.Lock()
+	cm.samples = samples
+	cm.timerSamples = timerSamples
+	cm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (cm *CommandMetrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range cm.descs {
+		ch <- desc
+	}
+	for _, timer := range cm.timers {
+		timer.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector
+func (cm *CommandMetrics) Collect(ch chan<- prometheus.Metric) {
+	cm.mu.Lock()
+	samples := cm.samples
+	timerSamples := cm.timerSamples
+	cm.mu.Unlock()
+
+	for _, sample := range samples {
+		desc, ok := cm.descs[sample.Metric]
+		if !ok {
+			continue
+		}
+		count := numberToFloat(sample.Data["Count"])
+
+		if isCommandMetric(commandCounterMetrics, sample.Metric) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, count, sample.Command, sample.Version)
+		} else {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, count, sample.Command, sample.Version)
+		}
+	}
+
+	for metric, timer := range cm.timers {
+		timer.collectSamples(ch, timerSamples[metric])
+	}
+}
+
+func isCommandMetric(metrics []string, metric string) bool {
+	for _, m := range metrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DLOMetrics 定义死信队列（dead letter office）指标
+// PuppetDB 将无法处理的命令写入死信队列，消息数增长说明命令被丢弃而不是被处理
+type DLOMetrics struct {
+	mu      sync.Mutex
+	metrics map[string]map[string]float64
+
+	messagesDesc        *prometheus.Desc
+	sizeDesc            *prometheus.Desc
+	commandMessagesDesc *prometheus.Desc
+	commandSizeDesc     *prometheus.Desc
+}
+
+// NewDLOMetrics 创建死信队列指标实例
+func NewDLOMetrics(namespace string) *DLOMetrics {
+	return &DLOMetrics{
+		messagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dlo", "messages"),
+			"Number of messages in the PuppetDB dead letter office.",
+			nil, nil,
+		),
+		sizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dlo", "size_bytes"),
+			"Total size of the messages in the PuppetDB dead letter office in bytes.",
+			nil, nil,
+		),
+		commandMessagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dlo", "command_messages"),
+			"Number of messages in the PuppetDB dead letter office, by command.",
+			[]string{"command"}, nil,
+		),
+		commandSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dlo", "command_size_bytes"),
+			"Size of the messages in the PuppetDB dead letter office in bytes, by command.",
+			[]string{"command"}, nil,
+		),
+	}
+}
+
+// Register 注册死信队列指标
+func (dm *DLOMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(dm)
+}
+
+// UpdateDLOMetrics 保存最新的死信队列数据，按命令（全局为 "global"）和指标（messages、filesize）分组
+func (dm *DLOMetrics) UpdateDLOMetrics(metrics map[string]map[string]float64) {
+	dm.mu.Lock()
+	dm.metrics = metrics
+	dm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (dm *DLOMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dm.messagesDesc
+	ch <- dm.sizeDesc
+	ch <- dm.commandMessagesDesc
+	ch <- dm.commandSizeDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (dm *DLOMetrics) Collect(ch chan<- prometheus.Metric) {
+	dm.mu.Lock()
+	metrics := dm.metrics
+	dm.mu.Unlock()
+
+	for command, values := range metrics {
+		messagesDesc, sizeDesc := dm.commandMessagesDesc, dm.commandSizeDesc
+		var labelValues []string
+		if command == "global" {
+			messagesDesc, sizeDesc = dm.messagesDesc, dm.sizeDesc
+		} else {
+			labelValues = []string{command}
+		}
+
+		if messages, ok := values["messages"]; ok {
+			ch <- prometheus.MustNewConstMetric(messagesDesc, prometheus.GaugeValue, messages, labelValues...)
+		}
+		if size, ok := values["filesize"]; ok {
+			ch <- prometheus.MustNewConstMetric(sizeDesc, prometheus.GaugeValue, size, labelValues...)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package exporter
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dropwizardQuantiles Dropwizard 快照字段到分位数的映射
+// Min/Max 是采样库的最小值和最大值，对应 0 和 1 分位数
+var dropwizardQuantiles = map[string]float64{
+	"Min":             0,
+	"50thPercentile":  0.5,
+	"75thPercentile":  0.75,
+	"95thPercentile":  0.95,
+	"98thPercentile":  0.98,
+	"99thPercentile":  0.99,
+	"999thPercentile": 0.999,
+	"Max":             1,
+}
+
+// dropwizardRates Dropwizard 速率字段到 window 标签的映射
+var dropwizardRates = map[string]string{
+	"OneMinuteRate":     "1m",
+	"FiveMinuteRate":    "5m",
+	"FifteenMinuteRate": "15m",
+	"MeanRate":          "mean",
+}
+
+// dropwizardDurationUnits DurationUnit 到秒的换算系数
+var dropwizardDurationUnits = map[string]float64{
+	"nanoseconds":  1e-9,
+	"microseconds": 1e-6,
+	"milliseconds": 1e-3,
+	"seconds":      1,
+	"minutes":      60,
+	"hours":        3600,
+	"days":         86400,
+}
+
+// dropwizardRateUnits RateUnit 时间部分到每秒的换算系数
+var dropwizardRateUnits = map[string]float64{
+	"nanosecond":  1e9,
+	"microsecond": 1e6,
+	"millisecond": 1e3,
+	"second":      1,
+	"minute":      1.0 / 60,
+	"hour":        1.0 / 3600,
+	"day":         1.0 / 86400,
+}
+
+// DropwizardOpts 定义 Dropwizard 指标转换后的名称和单位
+type DropwizardOpts struct {
+	Namespace string
+	Subsystem string
+	Name      string
+	Help      string
+	// Unit 为摘要名称的单位后缀，计时器通常为 "seconds"
+	Unit string
+	// Scale 用于没有 DurationUnit 的直方图，将原始值换算为 Unit，为 0 时不换算
+	Scale float64
+}
+
+// DropwizardSample 单个 Dropwizard MBean 的数据及其标签值
+type DropwizardSample struct {
+	LabelValues []string
+	Data        map[string]interface{}
+}
+
+// DropwizardMetric 将 Dropwizard Timer、Meter 和 Histogram 转换为 Prometheus 指标：
+// 带分位数的数据输出为摘要（<name>_<unit>），只有计数的 Meter 输出为计数器（<name>_total），
+// 带速率的数据额外输出每秒速率（<name>_rate{window}）
+type DropwizardMetric struct {
+	mu      sync.Mutex
+	scale   float64
+	descs   dropwizardDescs
+	samples []DropwizardSample
+}
+
+// dropwizardDescs 一个 Dropwizard 指标转换后的三个指标描述
+type dropwizardDescs struct {
+	summary *prometheus.Desc
+	total   *prometheus.Desc
+	rate    *prometheus.Desc
+}
+
+// NewDropwizardMetric 创建 Dropwizard 指标转换器
+func NewDropwizardMetric(opts DropwizardOpts, labels []string) *DropwizardMetric {
+	scale := opts.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	return &DropwizardMetric{
+		scale: scale,
+		descs: newDropwizardDescs(opts, labels),
+	}
+}
+
+func newDropwizardDescs(opts DropwizardOpts, labels []string) dropwizardDescs {
+	summaryName := opts.Name
+	if opts.Unit != "" {
+		summaryName += "_" + opts.Unit
+	}
+
+	return dropwizardDescs{
+		summary: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, opts.Subsystem, summaryName),
+			opts.Help,
+			labels, nil,
+		),
+		total: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name+"_total"),
+			opts.Help+" Total count of events.",
+			labels, nil,
+		),
+		rate: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name+"_rate"),
+			opts.Help+" Events per second over the given window.",
+			append(append([]string{}, labels...), "window"), nil,
+		),
+	}
+}
+
+// Register 注册 Dropwizard 指标
+func (dm *DropwizardMetric) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(dm)
+}
+
+// Update 以新的数据整体替换已保存的数据，忽略 Data 为 nil 的样本
+// 整体替换保证并发的 Collect 不会看到清空后尚未填充完整的数据
+func (dm *DropwizardMetric) Update(samples []DropwizardSample) {
+	kept := make([]DropwizardSample, 0, len(samples))
+	for _, sample := range samples {
+		if sample.Data != nil {
+			kept = append(kept, sample)
+		}
+	}
+
+	dm.mu.Lock()
+	dm.samples = kept
+	dm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (dm *DropwizardMetric) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dm.descs.summary
+	ch <- dm.descs.total
+	ch <- dm.descs.rate
+}
+
+// Collect 实现 prometheus.Collector
+func (dm *DropwizardMetric) Collect(ch chan<- prometheus.Metric) {
+	dm.mu.Lock()
+	samples := dm.samples
+	dm.mu.Unlock()
+
+	dm.collectSamples(ch, samples)
+}
+
+// collectSamples 输出给定的样本，供自行保存样本的收集器使用
+func (dm *DropwizardMetric) collectSamples(ch chan<- prometheus.Metric, samples []DropwizardSample) {
+	for _, sample := range samples {
+		if sample.Data != nil {
+			dm.descs.collect(ch, dm.scale, sample.Data, sample.LabelValues)
+		}
+	}
+}
+
+// collect 按数据中的字段判断 Dropwizard 类型并输出对应的指标
+func (d dropwizardDescs) collect(ch chan<- prometheus.Metric, scale float64, data map[string]interface{}, labelValues []string) {
+	count := numberToFloat(data["Count"])
+
+	if _, ok := data["Mean"]; ok {
+		// Timer 自带 DurationUnit，Histogram 没有单位信息，使用配置的换算系数
+		factor := scale
+		if unit, ok := data["DurationUnit"].(string); ok {
+			if f, ok := dropwizardDurationUnits[strings.ToLower(unit)]; ok {
+				factor = f
+			}
+		}
+
+		quantiles := make(map[float64]float64, len(dropwizardQuantiles))
+		for field, quantile := range dropwizardQuantiles {
+			if v, ok := data[field]; ok {
+				quantiles[quantile] = numberToFloat(v) * factor
+			}
+		}
+		// Dropwizard 不提供总和，使用 Mean * Count 近似
+		mean := numberToFloat(data["Mean"]) * factor
+		ch <- prometheus.MustNewConstSummary(d.summary, uint64(count), mean*count, quantiles, labelValues...)
+	} else if _, ok := data["Count"]; ok {
+		ch <- prometheus.MustNewConstMetric(d.total, prometheus.CounterValue, count, labelValues...)
+	}
+
+	perSecond := 1.0
+	if unit, ok := data["RateUnit"].(string); ok {
+		if i := strings.LastIndex(unit, "/"); i >= 0 {
+			if f, ok := dropwizardRateUnits[strings.TrimSuffix(strings.ToLower(unit[i+1:]), "s")]; ok {
+				perSecond = f
+			}
+		}
+	}
+	for field, window := range dropwizardRates {
+		if v, ok := data[field]; ok {
+			windowLabelValues := append(append([]string{}, labelValues...), window)
+			ch <- prometheus.MustNewConstMetric(d.rate, prometheus.GaugeValue, numberToFloat(v)*perSecond, windowLabelValues...)
+		}
+	}
+}
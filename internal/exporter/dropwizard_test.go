@@ -0,0 +1,140 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectDropwizard 收集 Dropwizard 指标，以指标描述和 "/" 分隔的标签值为键返回
+func collectDropwizard(t *testing.T, dm *DropwizardMetric) map[string]*dto.Metric {
+	ch := make(chan prometheus.Metric, 64)
+	dm.Collect(ch)
+	close(ch)
+
+	metrics := make(map[string]*dto.Metric)
+	for metric := range ch {
+		m := &dto.Metric{}
+		assert.NoError(t, metric.Write(m))
+		key := metric.Desc().String()
+		for _, label := range m.GetLabel() {
+			key += "/" + label.GetValue()
+		}
+		metrics[key] = m
+	}
+	return metrics
+}
+
+func TestDropwizardMetricCollect(t *testing.T) {
+	opts := DropwizardOpts{Namespace: "puppetdb", Name: "test", Unit: "seconds", Help: "Test."}
+	descs := newDropwizardDescs(opts, []string{"pool"})
+	summary, total, rate := descs.summary.String()+"/read", descs.total.String()+"/read", descs.rate.String()+"/read/"
+
+	tests := []struct {
+		name            string
+		scale           float64
+		data            map[string]interface{}
+		expectedSummary map[float64]float64
+		expectedCount   uint64
+		expectedSum     float64
+		expectedTotal   float64
+		expectedRates   map[string]float64
+	}{
+		{
+			name: "毫秒计时器",
+			data: map[string]interface{}{
+				"Count": float64(4), "Mean": float64(250), "Min": float64(100), "50thPercentile": float64(200),
+				"99thPercentile": float64(900), "Max": float64(1000), "StdDev": float64(50),
+				"OneMinuteRate": 0.5, "MeanRate": 0.25,
+				"DurationUnit": "milliseconds", "RateUnit": "events/second",
+			},
+			expectedSummary: map[float64]float64{0: 0.1, 0.5: 0.2, 0.99: 0.9, 1: 1},
+			expectedCount:   4,
+			expectedSum:     1,
+			expectedRates:   map[string]float64{"1m": 0.5, "mean": 0.25},
+		},
+		{
+			name: "每分钟速率换算为每秒",
+			data: map[string]interface{}{
+				"Count": float64(120), "FiveMinuteRate": float64(60), "FifteenMinuteRate": float64(30),
+				"RateUnit": "events/minute",
+			},
+			expectedTotal: 120,
+			expectedRates: map[string]float64{"5m": 1, "15m": 0.5},
+		},
+		{
+			name:            "没有单位的直方图使用 Scale",
+			scale:           1e-3,
+			data:            map[string]interface{}{"Count": float64(2), "Mean": float64(1500), "Max": float64(2000)},
+			expectedSummary: map[float64]float64{1: 2},
+			expectedCount:   2,
+			expectedSum:     3,
+		},
+		{
+			name:            "DurationUnit 优先于 Scale",
+			scale:           1e-3,
+			data:            map[string]interface{}{"Count": float64(1), "Mean": float64(2), "DurationUnit": "SECONDS"},
+			expectedSummary: map[float64]float64{},
+			expectedCount:   1,
+			expectedSum:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := opts
+			opts.Scale = tt.scale
+			dm := NewDropwizardMetric(opts, []string{"pool"})
+			dm.Update([]DropwizardSample{{LabelValues: []string{"read"}, Data: tt.data}, {LabelValues: []string{"write"}}})
+			metrics := collectDropwizard(t, dm)
+
+			if tt.expectedSummary != nil {
+				m := metrics[summary]
+				if assert.NotNil(t, m) {
+					quantiles := make(map[float64]float64)
+					for _, q := range m.GetSummary().GetQuantile() {
+						quantiles[q.GetQuantile()] = q.GetValue()
+					}
+					assert.InDeltaMapValues(t, tt.expectedSummary, quantiles, 1e-9)
+					assert.Equal(t, tt.expectedCount, m.GetSummary().GetSampleCount())
+					assert.InDelta(t, tt.expectedSum, m.GetSummary().GetSampleSum(), 1e-9)
+				}
+				assert.Nil(t, metrics[total])
+			} else {
+				assert.Nil(t, metrics[summary])
+				assert.Equal(t, tt.expectedTotal, metrics[total].GetCounter().GetValue())
+			}
+
+			rates := make(map[string]float64)
+			for window := range map[string]bool{"1m": true, "5m": true, "15m": true, "mean": true} {
+				if m, ok := metrics[rate+window]; ok {
+					rates[window] = m.GetGauge().GetValue()
+				}
+			}
+			if tt.expectedRates == nil {
+				tt.expectedRates = map[string]float64{}
+			}
+			assert.InDeltaMapValues(t, tt.expectedRates, rates, 1e-9)
+		})
+	}
+}
+
+func TestDropwizardMetricUpdate(t *testing.T) {
+	dm := NewDropwizardMetric(DropwizardOpts{Namespace: "puppetdb", Name: "test"}, []string{"pool"})
+
+	dm.Update([]DropwizardSample{
+		{LabelValues: []string{"read"}, Data: map[string]interface{}{"Count": float64(1)}},
+		{LabelValues: []string{"write"}, Data: map[string]interface{}{"Count": float64(2)}},
+	})
+	assert.Len(t, collectDropwizard(t, dm), 2)
+
+	// 新数据整体替换旧数据，消失的标签不再输出
+	dm.Update([]DropwizardSample{{LabelValues: []string{"read"}, Data: map[string]interface{}{"Count": float64(3)}}})
+	metrics := collectDropwizard(t, dm)
+	assert.Len(t, metrics, 1)
+	for _, m := range metrics {
+		assert.Equal(t, float64(3), m.GetCounter().GetValue())
+	}
+}
@@ -1,7 +1,12 @@
 package exporter
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,9 +18,129 @@ import (
 // Exporter implements the prometheus.Exporter interface, and exports PuppetDB metrics
 type Exporter struct {
 	client          *puppetdb.PuppetDB
+	statusClient    *puppetdb.PuppetDB
 	metricsClient   *puppetdb.MetricsClient
 	namespace       string
+	name            string
+	logger          *log.Entry
+	registry        *prometheus.Registry
 	metricsRegistry *MetricsRegistry
+	reportHistory   *ReportHistory
+	runIntervals    *RunIntervalResolver
+	runIntervalFact string
+	maintenance     *Maintenance
+	maintenanceFact string
+	healthWeights   HealthWeights
+	nodeLifecycle   *NodeLifecycle
+	nodePurgeTTL    time.Duration
+	purgeWarning    time.Duration
+	inventory       *Inventory
+	caClient        *puppetdb.CAClient
+	caRefresh       time.Duration
+	caLastScrape    time.Time
+	caSigned        []string
+	caRevoked       []string
+	puppetServers   map[string]*puppetdb.PuppetDB
+	statusLevel     string
+	mbeanRules      *MBeanRules
+	jvmThreads      bool
+	jvmThreadsSkip  bool
+	summaryStats    time.Duration
+	summaryTimeout  time.Duration
+	summaryOnce     sync.Once
+	clockSkew       time.Duration
+	metricsBackend  string
+}
+
+// Options 导出器配置
+type Options struct {
+	// Name 实例名称，监控多个实例时作为所有指标的 puppetdb 标签，为空时不添加标签
+	Name          string
+	URL           string
+	CertPath      string
+	CACertPath    string
+	KeyPath       string
+	SSLSkipVerify bool
+	// TokenPath 查询 API 的 RBAC 令牌文件（X-Authentication），为空时不发送令牌
+	TokenPath string
+	// StatusAPI /status/v1 的地址和认证配置，为空的字段沿用查询 API 的配置
+	StatusAPI APIEndpoint
+	// MetricsAPI /metrics/v1 和 /metrics/v2 的地址和认证配置，为空的字段沿用查询 API 的配置
+	MetricsAPI APIEndpoint
+	Categories map[string]struct{}
+	// UnreportedNode 无法确定运行间隔时使用的全局未报告阈值
+	UnreportedNode time.Duration
+	// ReportHistoryWindow 报告历史的回溯窗口，为 0 时不查询报告历史
+	ReportHistoryWindow time.Duration
+	// RunIntervalFact 保存节点运行间隔的事实名称，为空时不查询
+	RunIntervalFact string
+	// RunIntervalEnvironments 按环境覆盖的运行间隔
+	RunIntervalEnvironments map[string]time.Duration
+	// InferRunInterval 是否根据报告历史推断运行间隔
+	InferRunInterval bool
+	// UnreportedMissedRuns 错过多少次期望运行后将节点标记为未报告
+	UnreportedMissedRuns int
+	// MaintenanceFile 维护窗口计划文件（JSON），为空时不加载
+	MaintenanceFile string
+	// MaintenanceFact 标记节点处于维护状态的事实名称，为空时不查询
+	MaintenanceFact string
+	// HealthWeights 按名称覆盖的健康评分权重
+	HealthWeights map[string]float64
+	// NodePurgeTTL PuppetDB 的 node-purge-ttl，为 0 时表示不清理节点
+	NodePurgeTTL time.Duration
+	// NodePurgeWarning 节点距离被清理少于该时间时计入即将清理的节点
+	NodePurgeWarning time.Duration
+	// Inventory 期望节点清单的文件路径或 HTTP URL，为空时不对账
+	Inventory string
+	// InventoryRefresh 从 HTTP URL 重新获取节点清单的间隔
+	InventoryRefresh time.Duration
+	// CAURL Puppet Server CA 地址（例如 https://puppet:8140），为空时不查询证书状态
+	CAURL string
+	// CARefresh 重新获取证书状态的间隔
+	CARefresh time.Duration
+	// PuppetServerURLs 需要抓取状态的 Puppet Server 地址（例如 https://puppet:8140）
+	PuppetServerURLs []string
+	// StatusLevel 查询 /status/v1/services 的详细级别（critical/info/debug），为空时使用服务端默认值
+	StatusLevel string
+	// MBeanRulesFile MBean 到指标的映射规则文件（JSON），为空时不使用规则
+	MBeanRulesFile string
+	// MBeanRulesPrefix 规则生成的指标名称前缀
+	MBeanRulesPrefix string
+	// JVMThreadInspection 是否通过 Jolokia exec 检查 JVM 线程状态和死锁
+	JVMThreadInspection bool
+	// SummaryStatsInterval 获取 /pdb/admin/v1/summary-stats 的间隔，为 0 时不获取
+	SummaryStatsInterval time.Duration
+	// SummaryStatsTimeout 获取 /pdb/admin/v1/summary-stats 的超时时间，为 0 时不限制
+	SummaryStatsTimeout time.Duration
+}
+
+// APIEndpoint 单个 PuppetDB API 的地址、TLS 证书和令牌配置
+type APIEndpoint struct {
+	URL        string `json:"url,omitempty"`
+	CertPath   string `json:"cert_file,omitempty"`
+	KeyPath    string `json:"key_file,omitempty"`
+	CACertPath string `json:"ca_file,omitempty"`
+	TokenPath  string `json:"token_file,omitempty"`
+}
+
+// clientOptions 以查询 API 的配置为基础，覆盖 endpoint 中非空的字段
+func (endpoint APIEndpoint) clientOptions(base puppetdb.Options) *puppetdb.Options {
+	if endpoint.URL != "" {
+		base.URL = endpoint.URL
+	}
+	if endpoint.CertPath != "" {
+		base.CertPath = endpoint.CertPath
+	}
+	if endpoint.KeyPath != "" {
+		base.KeyPath = endpoint.KeyPath
+	}
+	if endpoint.CACertPath != "" {
+		base.CACertPath = endpoint.CACertPath
+	}
+	if endpoint.TokenPath != "" {
+		base.TokenPath = endpoint.TokenPath
+	}
+	return &base
 }
 
 var (
@@ -36,22 +161,137 @@ func convertReportMetrics(reportMetrics []puppetdb.ReportMetric) []ReportMetric
 	return result
 }
 
+// convertServiceInfo 将 /status/v1/services 中的服务状态转换为内部格式
+func convertServiceInfo(name string, info puppetdb.ServiceInfo) ServiceInfo {
+	svc := ServiceInfo{
+		Name:           name,
+		Version:        info.ServiceVersion,
+		State:          info.State,
+		Up:             info.State == "running",
+		DetailLevel:    info.DetailLevel,
+		StatusVersion:  info.ServiceStatusVersion,
+		Flags:          make(map[string]bool),
+		ReadDatabases:  make(map[string]bool),
+		WriteDatabases: make(map[string]bool),
+		Alerts:         make(map[string]int),
+	}
+
+	for _, key := range []string{"read_db_up?", "write_db_up?", "maintenance_mode?"} {
+		if v, ok := info.Status[key].(bool); ok {
+			svc.Flags[strings.TrimSuffix(key, "?")] = v
+		}
+	}
+	// 部分版本只提供 write_dbs_up?
+	if _, ok := svc.Flags["write_db_up"]; !ok {
+		if v, ok := info.Status["write_dbs_up?"].(bool); ok {
+			svc.Flags["write_db_up"] = v
+		}
+	}
+
+	if v, ok := info.Status["queue_depth"].(float64); ok {
+		svc.QueueDepth = int(v)
+		svc.HasQueueDepth = true
+	}
+
+	// read_db/write_db 形如 {"default": {"up?": true}}
+	parseDatabases := func(value interface{}, databases map[string]bool) {
+		dbs, _ := value.(map[string]interface{})
+		for database, status := range dbs {
+			if m, ok := status.(map[string]interface{}); ok {
+				if up, ok := m["up?"].(bool); ok {
+					databases[database] = up
+				}
+			}
+		}
+	}
+	parseDatabases(info.Status["read_db"], svc.ReadDatabases)
+	parseDatabases(info.Status["write_db"], svc.WriteDatabases)
+
+	for _, alert := range info.ActiveAlerts {
+		severity := "unknown"
+		if m, ok := alert.(map[string]interface{}); ok {
+			if s := toString(m["severity"]); s != "" {
+				severity = s
+			}
+		}
+		svc.Alerts[severity]++
+	}
+
+	return svc
+}
+
+// hasCorrectiveChanges 判断报告中是否存在纠正性变更
+func hasCorrectiveChanges(reportMetrics []puppetdb.ReportMetric) bool {
+	for _, rm := range reportMetrics {
+		if rm.Category == "resources" && rm.Name == "corrective_change" && rm.Value > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // NewPuppetDBExporter returns a new exporter of PuppetDB metrics.
-func NewPuppetDBExporter(url, certPath, caPath, keyPath string, sslSkipVerify bool, categories map[string]struct{}) (e *Exporter, err error) {
+func NewPuppetDBExporter(options *Options) (e *Exporter, err error) {
 	e = &Exporter{
 		namespace: "puppetdb",
+		name:      options.Name,
+		logger:    log.NewEntry(log.StandardLogger()),
+		registry:  prometheus.NewRegistry(),
 	}
+	if e.name != "" {
+		e.logger = e.logger.WithField(instanceLabel, e.name)
+	}
+
+	// 创建指标注册表，每个实例使用独立的注册表
+	e.metricsRegistry = NewMetricsRegistry(e.namespace, options.Categories)
+	e.metricsRegistry.RegisterAll(e.registry)
+
+	if options.ReportHistoryWindow > 0 {
+		e.reportHistory = NewReportHistory(options.ReportHistoryWindow)
+	}
+
+	// 运行间隔只能从报告历史推断，未启用报告历史时推断不会生效
+	var inferFrom *ReportHistory
+	if options.InferRunInterval {
+		if e.reportHistory == nil {
+			return nil, fmt.Errorf("inferring run intervals requires a report history window greater than 0")
+		}
+		inferFrom = e.reportHistory
+	}
+	e.runIntervals = NewRunIntervalResolver(options.RunIntervalEnvironments, options.UnreportedMissedRuns, options.UnreportedNode, inferFrom)
+	e.runIntervalFact = options.RunIntervalFact
+
+	e.maintenance = NewMaintenance(options.MaintenanceFile)
+	e.maintenanceFact = options.MaintenanceFact
+
+	e.statusLevel = options.StatusLevel
+	e.mbeanRules, err = NewMBeanRules(options.MBeanRulesFile, options.MBeanRulesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	e.jvmThreads = options.JVMThreadInspection
+	e.summaryStats = options.SummaryStatsInterval
+	e.summaryTimeout = options.SummaryStatsTimeout
+	e.nodeLifecycle = NewNodeLifecycle()
+	e.nodePurgeTTL = options.NodePurgeTTL
+	e.purgeWarning = options.NodePurgeWarning
 
-	// 创建指标注册表
-	e.metricsRegistry = NewMetricsRegistry(e.namespace, categories)
-	e.metricsRegistry.RegisterAll()
+	if options.Inventory != "" {
+		e.inventory = NewInventory(options.Inventory, options.InventoryRefresh)
+	}
+
+	e.healthWeights, err = NewHealthWeights(options.HealthWeights)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse health weights: %v", err)
+	}
 
 	opts := &puppetdb.Options{
-		URL:        url,
-		CertPath:   certPath,
-		CACertPath: caPath,
-		KeyPath:    keyPath,
-		SSLVerify:  sslSkipVerify,
+		URL:        options.URL,
+		CertPath:   options.CertPath,
+		CACertPath: options.CACertPath,
+		KeyPath:    options.KeyPath,
+		SSLVerify:  options.SSLSkipVerify,
+		TokenPath:  options.TokenPath,
 	}
 
 	e.client, err = puppetdb.NewClient(opts)
@@ -59,16 +299,69 @@ func NewPuppetDBExporter(url, certPath, caPath, keyPath string, sslSkipVerify bo
 		return nil, fmt.Errorf("failed to create PuppetDB client: %v", err)
 	}
 
-	// 创建MetricsClient - 必须在e.client初始化之后
-	e.metricsClient = puppetdb.NewMetricsClient(e.client)
+	e.statusClient, err = puppetdb.NewClient(options.StatusAPI.clientOptions(*opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PuppetDB status client: %v", err)
+	}
+
+	metricsAPIClient, err := puppetdb.NewClient(options.MetricsAPI.clientOptions(*opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PuppetDB metrics client: %v", err)
+	}
+
+	if options.CAURL != "" {
+		caOpts := *opts
+		caOpts.URL = options.CAURL
+		e.caClient, err = puppetdb.NewCAClient(&caOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Puppet CA client: %v", err)
+		}
+		e.caRefresh = options.CARefresh
+	}
+
+	e.puppetServers = make(map[string]*puppetdb.PuppetDB, len(options.PuppetServerURLs))
+	for _, serverURL := range options.PuppetServerURLs {
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Puppet Server URL: %v", err)
+		}
+		serverOpts := *opts
+		serverOpts.URL = serverURL
+		e.puppetServers[u.Host], err = puppetdb.NewClient(&serverOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Puppet Server client: %v", err)
+		}
+	}
+
+	// 创建MetricsClient，使用指标 API 的独立配置
+	e.metricsClient = puppetdb.NewMetricsClient(metricsAPIClient)
 	if err != nil {
 		log.Fatalf("failed to create new client: %s", err)
 		return
 	}
 
+	e.metricsRegistry.GetAPIMetrics().SetClients(map[string]*puppetdb.PuppetDB{
+		"query":   e.client,
+		"status":  e.statusClient,
+		"metrics": metricsAPIClient,
+	})
+
 	return
 }
 
+// Gatherer 返回该实例的指标，设置了实例名称时所有指标带有 puppetdb 标签
+func (e *Exporter) Gatherer() prometheus.Gatherer {
+	if e.name == "" {
+		return e.registry
+	}
+	return &labelGatherer{gatherer: e.registry, name: instanceLabel, value: e.name}
+}
+
+// Name 返回实例名称
+func (e *Exporter) Name() string {
+	return e.name
+}
+
 // Describe outputs PuppetDB metric descriptions
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	// 指标注册表会处理所有指标描述
@@ -81,53 +374,474 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	e.metricsRegistry.Collect(ch)
 }
 
-// Scrape scrapes PuppetDB and update metrics
-func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categories map[string]struct{}) {
-	var statuses map[string]int
+// scrapeReportHistory 获取上次抓取之后收到的报告并更新报告历史
+func (e *Exporter) scrapeReportHistory() {
+	now := e.puppetDBNow()
+	scrapeStart := time.Now()
+	reports, err := e.client.ReportsSince(e.reportHistory.Since(now))
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("reports", time.Since(scrapeStart).Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to get report history: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("reports", "connection_error")
+		return
+	}
+
+	for _, report := range reports {
+		endTime, err := time.Parse(time.RFC3339, report.EndTime)
+		if err != nil {
+			e.logger.Debugf("failed to parse report end time: %s", err)
+			continue
+		}
+		receiveTime, err := time.Parse(time.RFC3339, report.ReceiveTime)
+		if err != nil {
+			receiveTime = endTime
+		}
+		e.reportHistory.Add(report.Certname, ReportEntry{
+			Hash:        report.Hash,
+			Status:      report.Status,
+			Time:        endTime,
+			ReceiveTime: receiveTime,
+		})
+	}
+	e.reportHistory.Prune(now)
+}
+
+// scrapeRunIntervalFacts 获取保存运行间隔的事实
+func (e *Exporter) scrapeRunIntervalFacts() {
+	scrapeStart := time.Now()
+	facts, err := e.client.Facts(e.runIntervalFact)
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("facts", time.Since(scrapeStart).Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to get run interval facts: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("facts", "connection_error")
+		return
+	}
+
+	values := make(map[string]interface{}, len(facts))
+	for _, fact := range facts {
+		values[fact.Certname] = fact.Value
+	}
+	e.runIntervals.SetFacts(values)
+}
+
+// MaintenanceHandler 返回维护窗口 HTTP API 的处理器
+func (e *Exporter) MaintenanceHandler() http.Handler {
+	return e.maintenance
+}
+
+// scrapeMaintenance 重新加载维护计划文件并获取维护事实
+func (e *Exporter) scrapeMaintenance() {
+	if err := e.maintenance.Reload(); err != nil {
+		e.logger.Errorf("failed to reload maintenance windows: %s", err)
+	}
+
+	if e.maintenanceFact == "" {
+		return
+	}
+
+	scrapeStart := time.Now()
+	facts, err := e.client.Facts(e.maintenanceFact)
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("maintenance_facts", time.Since(scrapeStart).Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to get maintenance facts: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("maintenance_facts", "connection_error")
+		return
+	}
+
+	values := make(map[string]interface{}, len(facts))
+	for _, fact := range facts {
+		values[fact.Certname] = fact.Value
+	}
+	e.maintenance.SetFacts(values)
+}
+
+// matchMBeanRules 重新加载规则文件，返回 /metrics/v2/list 中与规则匹配的 MBean
+func (e *Exporter) matchMBeanRules() ([]string, error) {
+	if !e.mbeanRules.Enabled() {
+		return nil, nil
+	}
+
+	err := e.mbeanRules.Reload()
+	e.metricsRegistry.GetMBeanRuleMetrics().UpdateLoadSuccess(err == nil)
+	if err != nil {
+		e.logger.Errorf("failed to reload mbean rules: %s", err)
+	}
+
+	mbeans, err := e.metricsClient.ListMBeans()
+	if err != nil {
+		return nil, err
+	}
+	return e.mbeanRules.Match(mbeans), nil
+}
+
+// scrapeMBeanRules 按规则文件为批量读取结果中匹配的 MBean 生成指标，err 为匹配 MBean 时的错误
+func (e *Exporter) scrapeMBeanRules(snapshot *puppetdb.MetricsSnapshot, err error) {
+	if !e.mbeanRules.Enabled() {
+		return
+	}
+
+	var data map[string]map[string]interface{}
+	if err == nil {
+		data, err = snapshot.GetMBeans()
+	}
+	if e.checkMetricsRead("mbean_rules", err) {
+		e.metricsRegistry.GetMBeanRuleMetrics().UpdateSamples(e.mbeanRules.Evaluate(data))
+	}
+}
+
+// scrapeJVMThreads 通过 Jolokia exec 统计 JVM 线程状态，发现死锁时记录线程名称
+func (e *Exporter) scrapeJVMThreads() {
+	// /metrics/v1 不支持 exec 操作，回退期间跳过线程检查，只记录一次日志
+	if e.metricsBackend != "v2" {
+		if !e.jvmThreadsSkip {
+			e.logger.Infof("skipping jvm thread inspection: exec operations require /metrics/v2, current metrics API is %s", e.metricsBackend)
+			e.jvmThreadsSkip = true
+		}
+		e.metricsRegistry.GetJVMThreadMetrics().Reset()
+		return
+	}
+	e.jvmThreadsSkip = false
+
+	scrapeStart := time.Now()
+	threads, err := e.metricsClient.GetJVMThreadStates()
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("jvm_threads", time.Since(scrapeStart).Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to inspect jvm threads: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("jvm_threads", "connection_error")
+		return
+	}
+
+	if threads.Deadlocked > 0 {
+		e.logger.Warnf("%v jvm threads are deadlocked: %s", threads.Deadlocked, strings.Join(threads.DeadlockedNames, ", "))
+	}
+	e.metricsRegistry.GetJVMThreadMetrics().UpdateJVMThreadMetrics(threads.States, threads.Deadlocked)
+}
+
+// runSummaryStats 按间隔获取 PuppetDB 汇总统计
+// 该查询会扫描整个数据库，在独立的 goroutine 中执行，不阻塞其他指标的抓取；
+// 失败时同样等待一个间隔再重试，避免反复执行开销较大的查询
+func (e *Exporter) runSummaryStats() {
+	ticker := time.NewTicker(e.summaryStats)
+	defer ticker.Stop()
+
+	for {
+		e.scrapeSummaryStats()
+		<-ticker.C
+	}
+}
+
+// scrapeSummaryStats 获取一次 PuppetDB 汇总统计，超过 summaryTimeout 时中止请求
+func (e *Exporter) scrapeSummaryStats() {
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Errorf("summary stats scrape panicked: %v", r)
+		}
+	}()
+
+	ctx := context.Background()
+	if e.summaryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.summaryTimeout)
+		defer cancel()
+	}
+
+	scrapeStart := time.Now()
+	stats, err := e.client.SummaryStats(ctx)
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("summary_stats", time.Since(scrapeStart).Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to get summary stats: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("summary_stats", "connection_error")
+		return
+	}
+	e.metricsRegistry.GetSummaryStatsMetrics().UpdateSummaryStats(stats)
+}
+
+// scrapeMeta 获取 PuppetDB 版本和服务器时间，并记录 PuppetDB 与导出器之间的时钟偏差
+// 获取服务器时间失败时保留上一次的偏差
+func (e *Exporter) scrapeMeta() {
+	scrapeStart := time.Now()
+	version, err := e.client.Version()
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("version", time.Since(scrapeStart).Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to get puppetdb version: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("version", "connection_error")
+	} else {
+		e.metricsRegistry.GetMetaMetrics().UpdateVersion(version)
+		if e.metricsClient != nil {
+			e.metricsClient.ObserveVersion(version)
+		}
+	}
+
+	scrapeStart = time.Now()
+	serverTime, err := e.client.ServerTime()
+	elapsed := time.Since(scrapeStart)
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("server_time", elapsed.Seconds())
+	if err != nil {
+		e.logger.Errorf("failed to get puppetdb server time: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("server_time", "connection_error")
+		return
+	}
+	// 服务器时间近似对应请求往返的中点
+	e.clockSkew = serverTime.Sub(scrapeStart.Add(elapsed / 2))
+	e.metricsRegistry.GetMetaMetrics().UpdateClockSkew(e.clockSkew.Seconds())
+}
+
+// scrapeMetricsBackend 检测 PuppetDB 当前可用的指标 API（/metrics/v2 或 /metrics/v1），变化时记录日志
+func (e *Exporter) scrapeMetricsBackend() {
+	_, err := e.metricsClient.Backend()
+	if err != nil {
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("metrics_backend", "connection_error")
+	}
+
+	backend := e.metricsClient.BackendName()
+	if backend != e.metricsBackend {
+		if err != nil {
+			e.logger.Errorf("failed to detect puppetdb metrics API: %s", err)
+		} else {
+			e.logger.Infof("reading puppetdb metrics from /metrics/%s", backend)
+		}
+		e.metricsBackend = backend
+	}
+	e.metricsRegistry.GetMetaMetrics().UpdateMetricsBackend(backend)
+}
+
+// puppetDBNow 返回按时钟偏差校正后的 PuppetDB 当前时间，用于计算报告等 PuppetDB 时间戳的年龄
+func (e *Exporter) puppetDBNow() time.Time {
+	return time.Now().Add(e.clockSkew)
+}
+
+// checkMetricsRead 记录指标读取错误，部分 MBean 读取失败时仍使用已读取的数据，返回数据是否可用
+func (e *Exporter) checkMetricsRead(endpoint string, err error) bool {
+	if err == nil {
+		return true
+	}
+	if _, ok := err.(*puppetdb.ReadError); ok {
+		e.logger.Debugf("partial failure reading %s metrics: %s", endpoint, err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError(endpoint, "partial_failure")
+		return true
+	}
+	e.logger.Errorf("failed to read %s metrics: %s", endpoint, err)
+	e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError(endpoint, "connection_error")
+	return false
+}
+
+// updateInactiveNode 更新非活跃节点的生命周期指标，返回节点是否即将被清理
+func (e *Exporter) updateInactiveNode(node puppetdb.Node, now time.Time) bool {
+	environment := node.ReportEnvironment
+	if environment == "" {
+		environment = node.FactsEnvironment
+	}
+
+	var deactivated, expired time.Time
+	if node.Deactivated != "" {
+		t, err := time.Parse(time.RFC3339, node.Deactivated)
+		if err != nil {
+			e.logger.Debugf("failed to parse deactivation time: %s", err)
+		}
+		deactivated = t
+	}
+	if node.Expired != "" {
+		t, err := time.Parse(time.RFC3339, node.Expired)
+		if err != nil {
+			e.logger.Debugf("failed to parse expiry time: %s", err)
+		}
+		expired = t
+	}
 
-	unreportedDuration, err := time.ParseDuration(unreportedNode)
+	purgeIn, ok := e.metricsRegistry.GetLifecycleMetrics().UpdateInactiveNode(node.Certname, environment, deactivated, expired, e.nodePurgeTTL, now)
+	return ok && purgeIn <= e.purgeWarning
+}
+
+// reconcileInventory 加载期望节点清单并与 PuppetDB 中的活跃节点对账
+func (e *Exporter) reconcileInventory(present map[string]string) {
+	// 等待下次获取期间保留上一次的加载状态
+	attempted, err := e.inventory.Load(time.Now())
 	if err != nil {
-		log.Errorf("failed to parse unreported duration: %s", err)
+		e.logger.Errorf("failed to load inventory: %s", err)
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("inventory", "load_error")
+	}
+	if attempted {
+		e.metricsRegistry.GetInventoryMetrics().UpdateLoadStatus(err == nil, len(e.inventory.Nodes()))
+	}
+
+	// 从未成功加载时不输出对账结果，避免把所有节点报告为未知
+	if e.inventory.Nodes() == nil {
 		return
 	}
+	missing, unknown := e.inventory.Reconcile(present)
+	e.metricsRegistry.GetInventoryMetrics().UpdateReconciliation(missing, unknown, present)
+}
+
+// scrapeCA 按刷新间隔获取 Puppet CA 证书状态，并在节点列表可用时与 PuppetDB 节点对账
+func (e *Exporter) scrapeCA(nodes []puppetdb.Node, nodesOK bool) {
+	now := time.Now()
+	if now.Sub(e.caLastScrape) >= e.caRefresh {
+		scrapeStart := time.Now()
+		certs, err := e.caClient.CertificateStatuses()
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("ca", time.Since(scrapeStart).Seconds())
+		if err != nil {
+			e.logger.Errorf("failed to get certificate statuses: %s", err)
+			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("ca", "connection_error")
+		} else {
+			counts := make(map[string]int)
+			expiries := make(map[string]time.Time)
+			e.caSigned = e.caSigned[:0]
+			e.caRevoked = e.caRevoked[:0]
+			for _, cert := range certs {
+				counts[cert.State]++
+				switch cert.State {
+				case "signed":
+					e.caSigned = append(e.caSigned, cert.Name)
+					if expiry, err := cert.ExpiresAt(); err == nil {
+						expiries[cert.Name] = expiry
+					} else {
+						e.logger.Debugf("%s", err)
+					}
+				case "revoked":
+					e.caRevoked = append(e.caRevoked, cert.Name)
+				}
+			}
+			e.metricsRegistry.GetCAMetrics().UpdateCertificates(counts, expiries)
+			e.caLastScrape = now
+		}
+	}
+
+	if !nodesOK || e.caLastScrape.IsZero() {
+		return
+	}
+	caNodes := make(map[string]CAPuppetDBNode, len(nodes))
+	for _, node := range nodes {
+		environment := node.ReportEnvironment
+		if environment == "" {
+			environment = node.FactsEnvironment
+		}
+		caNodes[node.Certname] = CAPuppetDBNode{
+			Environment: environment,
+			Active:      node.Deactivated == "" && node.Expired == "",
+			HasReport:   node.ReportTimestamp != "",
+		}
+	}
+	e.metricsRegistry.GetCAMetrics().UpdateCrossReference(e.caSigned, e.caRevoked, caNodes)
+}
+
+// scrapePuppetServers 抓取所有 Puppet Server 的调试级别服务状态
+func (e *Exporter) scrapePuppetServers() {
+	for server, client := range e.puppetServers {
+		scrapeStart := time.Now()
+		services, err := client.ServicesAtLevel("debug")
+		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("puppetserver", time.Since(scrapeStart).Seconds())
+		if err != nil {
+			e.logger.Errorf("failed to get Puppet Server %s services: %s", server, err)
+			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("puppetserver", "connection_error")
+			e.metricsRegistry.GetPuppetServerMetrics().UpdateUp(server, false)
+			continue
+		}
+		e.metricsRegistry.GetPuppetServerMetrics().UpdateUp(server, true)
+		e.metricsRegistry.GetPuppetServerMetrics().UpdateStatus(server, parsePuppetServerStatus(services))
+	}
+}
+
+// Scrape scrapes PuppetDB and update metrics
+func (e *Exporter) Scrape(interval time.Duration) {
+	var statuses map[string]int
+	var health []NodeHealth
+
+	// 数据库汇总统计按自己的间隔在独立的 goroutine 中获取，抓取循环重启时不重复启动
+	if e.summaryStats > 0 {
+		e.summaryOnce.Do(func() {
+			go e.runSummaryStats()
+		})
+	}
 
 	for {
 		statuses = make(map[string]int)
+		health = make([]NodeHealth, 0)
 
 		// 记录节点抓取开始时间
 		scrapeStart := time.Now()
 		nodes, err := e.client.Nodes()
 		if err != nil {
-			log.Errorf("failed to get nodes: %s", err)
+			e.logger.Errorf("failed to get nodes: %s", err)
 			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("nodes", "connection_error")
 		}
 		// 记录节点抓取耗时
 		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("nodes", time.Since(scrapeStart).Seconds())
 
+		// 获取 PuppetDB 版本和服务器时间，并检测可用的指标 API
+		e.scrapeMeta()
+		if e.metricsClient != nil {
+			e.scrapeMetricsBackend()
+		}
+
+		// 增量获取报告历史
+		if e.reportHistory != nil {
+			e.scrapeReportHistory()
+		}
+
+		// 获取运行间隔事实
+		if e.runIntervalFact != "" {
+			e.scrapeRunIntervalFacts()
+		}
+
+		// 更新维护窗口
+		e.scrapeMaintenance()
+
 		// 重置指标
 		e.metricsRegistry.GetNodeMetrics().Reset()
 		e.metricsRegistry.GetServiceMetrics().Reset()
+		e.metricsRegistry.GetLifecycleMetrics().Reset()
 
+		// 节点时间戳由 PuppetDB 生成，使用 PuppetDB 的时钟计算年龄，避免导出器主机时钟漂移影响未报告判断
+		now := e.puppetDBNow()
+		approachingPurge := 0
+		activeNodes := make(map[string]bool, len(nodes))
+		presentNodes := make(map[string]string, len(nodes))
 		for _, node := range nodes {
-			var deactivated string
-			if node.Deactivated == "" {
-				deactivated = "false"
+			// 更新已停用和已过期节点的生命周期指标
+			active := node.Deactivated == "" && node.Expired == ""
+			activeNodes[node.Certname] = active
+			if active {
+				environment := node.ReportEnvironment
+				if environment == "" {
+					environment = node.FactsEnvironment
+				}
+				presentNodes[node.Certname] = environment
 			} else {
-				deactivated = "true"
+				if e.updateInactiveNode(node, now) {
+					approachingPurge++
+				}
+			}
+
+			// 处于维护窗口的节点计入 maintenance 状态，不参与健康统计
+			inMaintenance := active && e.maintenance.InMaintenance(node.Certname, node.ReportEnvironment, now)
+			if inMaintenance {
+				statuses["maintenance"]++
+				e.metricsRegistry.GetNodeMetrics().UpdateMaintenance(node.Certname, node.ReportEnvironment)
+			}
+
+			// 只有活跃且不在维护中的节点参与健康评分
+			countHealth := active && !inMaintenance
+			nodeHealth := NodeHealth{Environment: node.ReportEnvironment}
+			if nodeHealth.Environment == "" {
+				nodeHealth.Environment = node.FactsEnvironment
 			}
 
 			if node.ReportTimestamp == "" {
-				if deactivated == "false" {
+				if countHealth {
 					statuses["unreported"]++
+					nodeHealth.Unreported = true
+					health = append(health, nodeHealth)
 				}
 				continue
 			}
 			latestReport, err := time.Parse(time.RFC3339, node.ReportTimestamp)
 			if err != nil {
-				if deactivated == "false" {
+				if countHealth {
 					statuses["unreported"]++
+					nodeHealth.Unreported = true
+					health = append(health, nodeHealth)
 				}
-				log.Errorf("failed to parse report timestamp: %s", err)
+				e.logger.Errorf("failed to parse report timestamp: %s", err)
 				continue
 			}
 
@@ -146,30 +860,72 @@ func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categor
 				FactsTimestamp:          node.FactsTimestamp,
 			}
 
+			// 按节点解析期望的运行间隔和未报告阈值
+			runInterval, runIntervalSource := e.runIntervals.Resolve(node.Certname, node.ReportEnvironment)
+			unreportedDuration := e.runIntervals.Threshold(runInterval)
+
 			// 更新节点指标
-			e.metricsRegistry.GetNodeMetrics().UpdateNodeMetrics(nodeInfo, unreportedDuration, time.Now())
+			e.metricsRegistry.GetNodeMetrics().UpdateNodeMetrics(nodeInfo, unreportedDuration, now)
 
-			if deactivated == "false" {
-				if latestReport.Add(unreportedDuration).Before(time.Now()) {
+			if active {
+				if e.reportHistory != nil {
+					e.metricsRegistry.GetNodeMetrics().UpdateHistoryMetrics(nodeInfo, e.reportHistory.Stats(node.Certname), now)
+				}
+				missedRuns := MissedRuns(now.Sub(latestReport), runInterval)
+				e.metricsRegistry.GetNodeMetrics().UpdateRunIntervalMetrics(nodeInfo, runInterval, runIntervalSource, missedRuns)
+			}
+
+			if countHealth {
+				if latestReport.Add(unreportedDuration).Before(now) {
 					statuses["unreported"]++
+					nodeHealth.Unreported = true
 				} else if node.LatestReportStatus == "" {
 					statuses["unreported"]++
+					nodeHealth.Unreported = true
 				} else {
 					statuses[node.LatestReportStatus]++
+					nodeHealth.Failed = node.LatestReportStatus == "failed"
 				}
+				nodeHealth.NoopPending = node.LatestReportNoopPending
+				nodeHealth.CachedCatalog = node.CachedCatalogStatus != "" && node.CachedCatalogStatus != "not_used"
 			}
 
 			if node.LatestReportHash != "" {
 				reportMetrics, _ := e.client.ReportMetrics(node.LatestReportHash)
 				e.metricsRegistry.GetNodeMetrics().UpdateReportMetrics(nodeInfo, convertReportMetrics(reportMetrics))
+				nodeHealth.CorrectiveChanges = hasCorrectiveChanges(reportMetrics)
+			}
+
+			if countHealth {
+				health = append(health, nodeHealth)
 			}
 		}
 
+		e.metricsRegistry.GetLifecycleMetrics().UpdateApproachingPurge(approachingPurge)
+		if err == nil {
+			e.metricsRegistry.GetLifecycleMetrics().RecordChurn(e.nodeLifecycle.Observe(activeNodes))
+		}
+
+		// 获取 Puppet CA 证书状态并与节点对账
+		if e.caClient != nil {
+			e.scrapeCA(nodes, err == nil)
+		}
+
+		// 抓取 Puppet Server 状态
+		if len(e.puppetServers) > 0 {
+			e.scrapePuppetServers()
+		}
+
+		// 对比期望节点清单
+		if e.inventory != nil && err == nil {
+			e.reconcileInventory(presentNodes)
+		}
+
 		// Scrape service status endpoints and expose metrics
 		serviceScrapeStart := time.Now()
-		services, serr := e.client.Services()
+		services, serr := e.statusClient.ServicesAtLevel(e.statusLevel)
 		if serr != nil {
-			log.Errorf("failed to get services: %s", serr)
+			e.logger.Errorf("failed to get services: %s", serr)
 			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("services", "connection_error")
 		}
 		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("services", time.Since(serviceScrapeStart).Seconds())
@@ -178,47 +934,51 @@ func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categor
 			// 转换服务信息格式
 			serviceInfos := make([]ServiceInfo, 0)
 			for svcName, info := range services {
-				serviceInfo := ServiceInfo{
-					Name:    svcName,
-					Version: info.ServiceVersion,
-					State:   info.State,
-					Up:      true, // 简化处理，假设服务正常运行
-				}
-				serviceInfos = append(serviceInfos, serviceInfo)
+				serviceInfos = append(serviceInfos, convertServiceInfo(svcName, info))
 			}
 			e.metricsRegistry.GetServiceMetrics().UpdateServiceMetrics(serviceInfos)
 		}
 
-		// Scrape /metrics/v2 and expose useful values
-		metricsV2ScrapeStart := time.Now()
-		metricsV2, merr := e.client.MetricsV2()
-		if merr != nil {
-			log.Errorf("failed to get metrics v2: %s", merr)
-			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("metrics_v2", "connection_error")
-		}
-		e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("metrics_v2", time.Since(metricsV2ScrapeStart).Seconds())
+		// Scrape /metrics/v2 and expose useful values (only available with the Jolokia backend)
+		if e.metricsBackend == "v2" {
+			metricsV2ScrapeStart := time.Now()
+			metricsV2, merr := e.metricsClient.MetricsV2()
+			if merr != nil {
+				e.logger.Errorf("failed to get metrics v2: %s", merr)
+				e.metricsRegistry.GetPerformanceMetrics().RecordScrapeError("metrics_v2", "connection_error")
+			}
+			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("metrics_v2", time.Since(metricsV2ScrapeStart).Seconds())
 
-		if merr == nil {
-			// 转换 metrics v2 数据格式
-			metricsV2Data := MetricsV2Data{
-				Status:    metricsV2.Status,
-				Timestamp: metricsV2.Timestamp,
-				Value:     metricsV2.Value,
+			if merr == nil {
+				// 转换 metrics v2 数据格式
+				metricsV2Data := MetricsV2Data{
+					Status:    metricsV2.Status,
+					Timestamp: metricsV2.Timestamp,
+					Value:     metricsV2.Value,
+				}
+				e.metricsRegistry.GetMetricsV2().UpdateMetricsV2(metricsV2Data)
 			}
-			e.metricsRegistry.GetMetricsV2().UpdateMetricsV2(metricsV2Data)
 		}
 
 		// 更新节点状态计数
 		e.metricsRegistry.GetNodeMetrics().UpdateStatusCount(statuses)
 
-		// 更新系统健康评分
-		e.metricsRegistry.GetSystemMetrics().UpdateSystemMetrics(statuses)
+		// 更新系统健康评分，节点查询失败时保留上一次的评分
+		if err == nil {
+			e.metricsRegistry.GetSystemMetrics().UpdateSystemMetrics(health, e.healthWeights)
+		}
 
 		// 收集PuppetDB核心指标
 		if e.metricsClient != nil {
+			// 在一次批量读取中读取全部核心指标和规则匹配的 MBean，各组指标从读取结果中解析
+			ruleMBeans, ruleErr := e.matchMBeanRules()
+			readStart := time.Now()
+			snapshot := e.metricsClient.ReadSnapshot(ruleMBeans...)
+			e.metricsRegistry.GetPerformanceMetrics().RecordScrapeDuration("metrics", time.Since(readStart).Seconds())
+
 			// 收集人口统计指标
-			populationMetrics, err := e.metricsClient.GetPopulationMetrics()
-			if err == nil {
+			populationMetrics, err := snapshot.GetPopulationMetrics()
+			if e.checkMetricsRead("population", err) {
 				e.metricsRegistry.GetPuppetDBMetrics().UpdatePopulationMetrics(
 					populationMetrics["nodes"],
 					populationMetrics["resources"],
@@ -227,30 +987,41 @@ func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categor
 			}
 
 			// 收集存储层指标
-			storageMetrics, err := e.metricsClient.GetStorageMetrics()
-			if err == nil {
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateStorageMetrics(
-					storageMetrics["duplicate_pct"],
-					storageMetrics["gc_time"],
-					storageMetrics["replace_facts_time"],
-					storageMetrics["replace_catalog_time"],
-				)
+			storageMetrics, err := snapshot.GetStorageMetrics()
+			if e.checkMetricsRead("storage", err) {
+				e.metricsRegistry.GetStorageMetrics().UpdateStorageMetrics(storageMetrics)
 			}
 
-			// 收集命令处理指标
-			commandMetrics, err := e.metricsClient.GetCommandMetrics()
-			if err == nil && commandMetrics["global"] != nil {
-				global := commandMetrics["global"]
-				if depth, ok := global["depth"]; ok {
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateCommandMetrics("global", "", "", 0)
-					// 设置队列深度
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateCommandQueueDepth(depth)
+			// 收集按命令和版本划分的消息队列指标
+			commandMBeans, err := snapshot.GetPerCommandMetrics()
+			if e.checkMetricsRead("per_command", err) {
+				samples := make([]CommandMBeanSample, len(commandMBeans))
+				for i, mbean := range commandMBeans {
+					samples[i] = CommandMBeanSample{
+						Command: mbean.Command,
+						Version: mbean.Version,
+						Metric:  mbean.Metric,
+						Data:    mbean.Data,
+					}
 				}
+				e.metricsRegistry.GetCommandMetrics().UpdateCommandMetrics(samples)
+			}
+
+			// 收集死信队列指标
+			dloMetrics, err := snapshot.GetDLOMetrics()
+			if e.checkMetricsRead("dlo", err) {
+				e.metricsRegistry.GetDLOMetrics().UpdateDLOMetrics(dloMetrics)
+			}
+
+			// 收集定期清理操作指标
+			adminMetrics, err := snapshot.GetAdminMetrics()
+			if e.checkMetricsRead("admin", err) {
+				e.metricsRegistry.GetAdminMetrics().UpdateAdminMetrics(adminMetrics)
 			}
 
 			// 收集数据库指标
-			dbMetrics, err := e.metricsClient.GetDBMetrics()
-			if err == nil {
+			dbMetrics, err := snapshot.GetDBMetrics()
+			if e.checkMetricsRead("database", err) {
 				for pool, metrics := range dbMetrics {
 					e.metricsRegistry.GetPuppetDBMetrics().UpdateDBMetrics(
 						pool,
@@ -272,60 +1043,15 @@ func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categor
 				}
 			}
 
-			// 收集数据库连接池高级统计指标
-			dbPoolStats, err := e.metricsClient.GetDBPoolUsageMetrics()
-			if err == nil {
-				for pool, stats := range dbPoolStats {
-					// 更新使用统计
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateDBPoolUsageStats(
-						pool,
-						stats["UsageMean"],
-						stats["Usage75thPercentile"],
-						stats["Usage95thPercentile"],
-						stats["Usage99thPercentile"],
-						stats["UsageMax"],
-					)
-					// 更新等待时间统计
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateDBPoolWaitStats(
-						pool,
-						stats["WaitMean"],
-						stats["Wait75thPercentile"],
-						stats["Wait95thPercentile"],
-						stats["Wait99thPercentile"],
-						stats["WaitMax"],
-					)
-				}
-			}
-
-			// 收集数据库连接池连接创建和超时率统计指标
-			dbPoolCreationStats, err := e.metricsClient.GetDBPoolConnectionCreationMetrics()
-			if err == nil {
-				for pool, stats := range dbPoolCreationStats {
-					// 更新连接创建统计
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateDBPoolConnectionCreationStats(
-						pool,
-						stats["ConnectionCreationMean"],
-						stats["ConnectionCreation75thPercentile"],
-						stats["ConnectionCreation95thPercentile"],
-						stats["ConnectionCreation99thPercentile"],
-						stats["ConnectionCreationMax"],
-						stats["ConnectionCreationCount"],
-					)
-					// 更新连接超时率统计
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateDBPoolConnectionTimeoutRateStats(
-						pool,
-						stats["ConnectionTimeoutRateOneMinute"],
-						stats["ConnectionTimeoutRateFiveMinute"],
-						stats["ConnectionTimeoutRateFifteenMinute"],
-						stats["ConnectionTimeoutRateMean"],
-						stats["ConnectionTimeoutRateCount"],
-					)
-				}
+			// 收集数据库连接池 Dropwizard 统计指标
+			dbPoolStats, err := snapshot.GetDBPoolStats()
+			if e.checkMetricsRead("database_pool_stats", err) {
+				e.metricsRegistry.GetPuppetDBMetrics().UpdateDBPoolStats(dbPoolStats)
 			}
 
 			// 收集JVM指标
-			jvmMetrics, err := e.metricsClient.GetJVMMetrics()
-			if err == nil {
+			jvmMetrics, err := snapshot.GetJVMMetrics()
+			if e.checkMetricsRead("jvm", err) {
 				// 更新内存指标
 				if used, ok := jvmMetrics["memory_HeapMemoryUsage_used"]; ok {
 					e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMMetrics("heap", used, -1, -1, "", 0)
@@ -339,45 +1065,25 @@ func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categor
 				}
 			}
 
-			// 收集详细的JVM指标（包括内存池、垃圾收集器、运行时系统等）
-			jvmDetailedMetrics, err := e.metricsClient.GetJVMComprehensiveMetrics()
-			if err == nil {
-				// 更新内存池指标
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMHeapMemoryPoolMetrics(
-					"g1_eden_space",
-					jvmDetailedMetrics["jvm_memory_pool_g1_eden_space_used_bytes"],
-					-1, -1, -1,
-				)
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMHeapMemoryPoolMetrics(
-					"g1_old_gen",
-					jvmDetailedMetrics["jvm_memory_pool_g1_old_gen_used_bytes"],
-					-1, -1, -1,
-				)
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMHeapMemoryPoolMetrics(
-					"g1_survivor_space",
-					jvmDetailedMetrics["jvm_memory_pool_g1_survivor_space_used_bytes"],
-					-1, -1, -1,
-				)
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMHeapMemoryPoolMetrics(
-					"metaspace",
-					jvmDetailedMetrics["jvm_memory_pool_metaspace_used_bytes"],
-					-1, -1, -1,
-				)
-
-				// 更新垃圾收集器指标
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMGarbageCollectorMetrics(
-					"g1_young_generation",
-					jvmDetailedMetrics["jvm_gc_g1_young_generation_collection_count"],
-					jvmDetailedMetrics["jvm_gc_g1_young_generation_collection_time_seconds"],
-					-1,
-				)
-				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMGarbageCollectorMetrics(
-					"g1_old_generation",
-					jvmDetailedMetrics["jvm_gc_g1_old_generation_collection_count"],
-					jvmDetailedMetrics["jvm_gc_g1_old_generation_collection_time_seconds"],
-					-1,
-				)
+			// 收集JVM内存池、垃圾收集器和缓冲池指标，池的名称从 MBean 中发现
+			jvmPools, err := snapshot.GetJVMPools()
+			if e.checkMetricsRead("jvm_pools", err) {
+				pm := e.metricsRegistry.GetPuppetDBMetrics()
+				pm.ResetJVMPools()
+				for _, pool := range jvmPools.MemoryPools {
+					pm.UpdateJVMHeapMemoryPoolMetrics(pool.Name, pool.Used, pool.Committed, pool.Max, pool.PeakUsed)
+				}
+				for _, gc := range jvmPools.GarbageCollectors {
+					pm.UpdateJVMGarbageCollectorMetrics(gc.Name, gc.CollectionCount, gc.CollectionTime, -1)
+				}
+				for _, pool := range jvmPools.BufferPools {
+					pm.UpdateJVMBufferPoolMetrics(pool.Name, pool.Count, pool.MemoryUsed, pool.TotalCapacity)
+				}
+			}
 
+			// 收集详细的JVM指标（包括类加载、编译、运行时系统等）
+			jvmDetailedMetrics, err := snapshot.GetJVMComprehensiveMetrics()
+			if e.checkMetricsRead("jvm_comprehensive", err) {
 				// 更新类加载指标
 				e.metricsRegistry.GetPuppetDBMetrics().UpdateJVMClassLoadingMetrics(
 					jvmDetailedMetrics["jvm_class_loading_loaded_class_count"],
@@ -425,12 +1131,24 @@ func (e *Exporter) Scrape(interval time.Duration, unreportedNode string, categor
 				)
 			}
 
+			// 检查JVM线程状态和死锁
+			if e.jvmThreads {
+				e.scrapeJVMThreads()
+			}
+
+			// 收集 Jetty Web 服务器指标
+			jettyMetrics, err := snapshot.GetJettyMetrics()
+			if e.checkMetricsRead("jetty", err) {
+				e.metricsRegistry.GetJettyMetrics().UpdateJettyMetrics(jettyMetrics)
+			}
+
+			// 收集规则文件定义的 MBean 指标
+			e.scrapeMBeanRules(snapshot, ruleErr)
+
 			// 收集HTTP详细指标
-			httpMetrics, err := e.metricsClient.GetHTTPMetrics()
-			if err == nil {
-				for endpoint, metrics := range httpMetrics {
-					e.metricsRegistry.GetPuppetDBMetrics().UpdateHTTPDetailedMetrics(endpoint, metrics)
-				}
+			httpMetrics, err := snapshot.GetHTTPMetrics()
+			if e.checkMetricsRead("http", err) {
+				e.metricsRegistry.GetPuppetDBMetrics().UpdateHTTPDetailedMetrics(httpMetrics)
 			}
 
 			time.Sleep(interval)
@@ -0,0 +1,114 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// instanceLabel 多实例时添加到所有指标上的标签名称
+// 不使用 instance，避免与 Prometheus 抓取目标的 instance 标签冲突
+const instanceLabel = "puppetdb"
+
+// InstanceConfig 实例文件中单个 PuppetDB 实例的配置，未设置的字段沿用命令行参数
+type InstanceConfig struct {
+	// Name 实例名称，作为 puppetdb 标签的值
+	Name       string      `json:"name"`
+	URL        string      `json:"url"`
+	CertPath   string      `json:"cert_file,omitempty"`
+	KeyPath    string      `json:"key_file,omitempty"`
+	CACertPath string      `json:"ca_file,omitempty"`
+	TokenPath  string      `json:"token_file,omitempty"`
+	Status     APIEndpoint `json:"status"`
+	Metrics    APIEndpoint `json:"metrics"`
+	// CAURL、PuppetServerURLs 和 Inventory 指向各实例自己的抓取目标，不沿用命令行参数
+	CAURL            string   `json:"ca_url,omitempty"`
+	PuppetServerURLs []string `json:"puppetserver_urls,omitempty"`
+	Inventory        string   `json:"inventory,omitempty"`
+	MaintenanceFile  string   `json:"maintenance_file,omitempty"`
+}
+
+// LoadInstances 从 JSON 文件加载实例列表，以 base（命令行参数）为基础为每个实例生成配置
+func LoadInstances(file string, base Options) ([]*Options, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instances file: %s", err)
+	}
+	var instances []InstanceConfig
+	if err := json.Unmarshal(content, &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse instances file: %s", err)
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no instance defined in %s", file)
+	}
+
+	names := make(map[string]bool, len(instances))
+	result := make([]*Options, 0, len(instances))
+	for i, instance := range instances {
+		if instance.Name == "" {
+			return nil, fmt.Errorf("instance %d: missing name", i)
+		}
+		if names[instance.Name] {
+			return nil, fmt.Errorf("instance %s: duplicate name", instance.Name)
+		}
+		names[instance.Name] = true
+		if instance.URL == "" {
+			return nil, fmt.Errorf("instance %s: missing url", instance.Name)
+		}
+
+		options := base
+		options.Name = instance.Name
+		options.URL = instance.URL
+		if instance.CertPath != "" {
+			options.CertPath = instance.CertPath
+		}
+		if instance.KeyPath != "" {
+			options.KeyPath = instance.KeyPath
+		}
+		if instance.CACertPath != "" {
+			options.CACertPath = instance.CACertPath
+		}
+		if instance.TokenPath != "" {
+			options.TokenPath = instance.TokenPath
+		}
+		// 状态和指标 API 的配置不继承命令行参数，未设置的字段沿用该实例的查询 API
+		options.StatusAPI = instance.Status
+		options.MetricsAPI = instance.Metrics
+		// 沿用命令行中的 CA、Puppet Server 和清单会让每个实例重复抓取同一目标，
+		// 并把清单与每个实例的部分节点对账
+		options.CAURL = instance.CAURL
+		options.PuppetServerURLs = instance.PuppetServerURLs
+		options.Inventory = instance.Inventory
+		if instance.MaintenanceFile != "" {
+			options.MaintenanceFile = instance.MaintenanceFile
+		}
+		result = append(result, &options)
+	}
+	return result, nil
+}
+
+// labelGatherer 为收集到的所有指标添加固定标签
+type labelGatherer struct {
+	gatherer prometheus.Gatherer
+	name     string
+	value    string
+}
+
+// Gather 实现 prometheus.Gatherer，标签按名称排序插入
+func (lg *labelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := lg.gatherer.Gather()
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			name, value := lg.name, lg.value
+			metric.Label = append(metric.Label, &dto.LabelPair{Name: &name, Value: &value})
+			sort.Slice(metric.Label, func(i, j int) bool {
+				return metric.Label[i].GetName() < metric.Label[j].GetName()
+			})
+		}
+	}
+	return families, err
+}
@@ -0,0 +1,119 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadInstances(t *testing.T) {
+	base := Options{
+		URL:              "https://puppetdb:8081",
+		CertPath:         "/etc/exporter/cert.pem",
+		KeyPath:          "/etc/exporter/key.pem",
+		CACertPath:       "/etc/exporter/ca.pem",
+		TokenPath:        "/etc/exporter/token",
+		StatusAPI:        APIEndpoint{URL: "https://puppetdb:8443"},
+		MetricsAPI:       APIEndpoint{URL: "https://puppetdb:8443"},
+		CAURL:            "https://puppet:8140",
+		PuppetServerURLs: []string{"https://puppet:8140"},
+		Inventory:        "/etc/exporter/inventory.csv",
+		MaintenanceFile:  "/etc/exporter/maintenance.json",
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []Options
+		wantErr  bool
+	}{
+		{
+			name: "未设置的字段沿用命令行参数",
+			content: `[
+				{"name": "dc1", "url": "https://dc1:8081"},
+				{"name": "dc2", "url": "https://dc2:8081", "cert_file": "/dc2/cert.pem", "key_file": "/dc2/key.pem", "ca_file": "/dc2/ca.pem",
+				 "token_file": "/dc2/token", "maintenance_file": "/dc2/maintenance.json",
+				 "status": {"url": "https://dc2:8443"}, "ca_url": "https://puppet-dc2:8140",
+				 "puppetserver_urls": ["https://puppet-dc2:8140"], "inventory": "/dc2/inventory.csv"}
+			]`,
+			expected: []Options{
+				{
+					Name: "dc1", URL: "https://dc1:8081",
+					CertPath: base.CertPath, KeyPath: base.KeyPath, CACertPath: base.CACertPath, TokenPath: base.TokenPath,
+					MaintenanceFile: base.MaintenanceFile,
+				},
+				{
+					Name: "dc2", URL: "https://dc2:8081",
+					CertPath: "/dc2/cert.pem", KeyPath: "/dc2/key.pem", CACertPath: "/dc2/ca.pem", TokenPath: "/dc2/token",
+					MaintenanceFile: "/dc2/maintenance.json",
+					StatusAPI:       APIEndpoint{URL: "https://dc2:8443"},
+					CAURL:           "https://puppet-dc2:8140", PuppetServerURLs: []string{"https://puppet-dc2:8140"},
+					Inventory: "/dc2/inventory.csv",
+				},
+			},
+		},
+		{name: "缺少名称", content: `[{"url": "https://dc1:8081"}]`, wantErr: true},
+		{name: "名称重复", content: `[{"name": "dc1", "url": "https://dc1:8081"}, {"name": "dc1", "url": "https://dc2:8081"}]`, wantErr: true},
+		{name: "缺少 url", content: `[{"name": "dc1"}]`, wantErr: true},
+		{name: "没有实例", content: `[]`, wantErr: true},
+		{name: "JSON 格式错误", content: `{"name": "dc1"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "instances.json")
+			assert.NoError(t, os.WriteFile(file, []byte(tt.content), 0644))
+
+			instances, err := LoadInstances(file, base)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if assert.Len(t, instances, len(tt.expected)) {
+				for i, expected := range tt.expected {
+					assert.Equal(t, expected, *instances[i])
+				}
+			}
+		})
+	}
+
+	_, err := LoadInstances(filepath.Join(t.TempDir(), "missing.json"), base)
+	assert.Error(t, err)
+}
+
+func TestLabelGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_value", Help: "Test."}, []string{"zone", "app"})
+	registry.MustRegister(gauge)
+	gauge.WithLabelValues("eu", "web").Set(1)
+
+	families, err := (&labelGatherer{gatherer: registry, name: instanceLabel, value: "dc1"}).Gather()
+	assert.NoError(t, err)
+	if assert.Len(t, families, 1) && assert.Len(t, families[0].GetMetric(), 1) {
+		// 添加的标签按名称排序插入
+		labels := make([][2]string, 0)
+		for _, label := range families[0].GetMetric()[0].GetLabel() {
+			labels = append(labels, [2]string{label.GetName(), label.GetValue()})
+		}
+		assert.Equal(t, [][2]string{{"app", "web"}, {"puppetdb", "dc1"}, {"zone", "eu"}}, labels)
+	}
+
+	// 多个实例的 Gatherers 合并时指标按 puppetdb 标签区分
+	other := prometheus.NewRegistry()
+	otherGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_value", Help: "Test."}, []string{"zone", "app"})
+	other.MustRegister(otherGauge)
+	otherGauge.WithLabelValues("eu", "web").Set(2)
+
+	families, err = prometheus.Gatherers{
+		&labelGatherer{gatherer: registry, name: instanceLabel, value: "dc1"},
+		&labelGatherer{gatherer: other, name: instanceLabel, value: "dc2"},
+	}.Gather()
+	assert.NoError(t, err)
+	if assert.Len(t, families, 1) {
+		assert.Len(t, families[0].GetMetric(), 2)
+	}
+}
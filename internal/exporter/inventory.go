@@ -0,0 +1,183 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Inventory 期望被管理的节点清单，来源可以是 CSV/JSON 文件或 HTTP URL
+type Inventory struct {
+	source   string
+	refresh  time.Duration
+	client   *http.Client
+	loadedAt time.Time
+	// attemptedAt 最近一次从 URL 获取的时间（无论成功与否），用于失败后的退避
+	attemptedAt time.Time
+	modTime     time.Time
+	// nodes 以小写 certname 为键，值为清单中的原始 certname
+	nodes map[string]string
+}
+
+// NewInventory 创建节点清单，source 以 http:// 或 https:// 开头时从 URL 获取，
+// 并每隔 refresh 重新获取一次；否则从文件读取并在文件变化时重新加载
+func NewInventory(source string, refresh time.Duration) *Inventory {
+	return &Inventory{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Load 按需重新加载节点清单，返回本次是否尝试了加载
+// 从 URL 获取时无论成功与否都等待 refresh 后才再次获取，避免 URL 不可用时每次抓取都等待超时
+func (inv *Inventory) Load(now time.Time) (bool, error) {
+	if inv.isURL() {
+		if !inv.attemptedAt.IsZero() && now.Sub(inv.attemptedAt) < inv.refresh {
+			return false, nil
+		}
+		inv.attemptedAt = now
+		return true, inv.loadURL(now)
+	}
+	return true, inv.loadFile(now)
+}
+
+// Nodes 返回已加载的期望节点（小写 certname 到原始 certname 的映射），尚未成功加载时返回 nil
+func (inv *Inventory) Nodes() map[string]string {
+	return inv.nodes
+}
+
+// Reconcile 对比期望节点与 PuppetDB 中的节点，返回缺失的节点和清单中未知的节点
+// certname 比较不区分大小写，返回值保留清单和 PuppetDB 中的原始 certname
+func (inv *Inventory) Reconcile(present map[string]string) (missing []string, unknown []string) {
+	presentKeys := make(map[string]struct{}, len(present))
+	for certname := range present {
+		key := strings.ToLower(certname)
+		presentKeys[key] = struct{}{}
+		if _, ok := inv.nodes[key]; !ok {
+			unknown = append(unknown, certname)
+		}
+	}
+	for key, certname := range inv.nodes {
+		if _, ok := presentKeys[key]; !ok {
+			missing = append(missing, certname)
+		}
+	}
+	return
+}
+
+func (inv *Inventory) isURL() bool {
+	return strings.HasPrefix(inv.source, "http://") || strings.HasPrefix(inv.source, "https://")
+}
+
+func (inv *Inventory) loadFile(now time.Time) error {
+	info, err := os.Stat(inv.source)
+	if err != nil {
+		return fmt.Errorf("failed to stat inventory file: %s", err)
+	}
+	if inv.nodes != nil && info.ModTime().Equal(inv.modTime) {
+		return nil
+	}
+
+	content, err := os.ReadFile(inv.source)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory file: %s", err)
+	}
+	nodes, err := parseInventory(content, strings.HasSuffix(strings.ToLower(inv.source), ".csv"))
+	if err != nil {
+		return err
+	}
+
+	inv.nodes = nodes
+	inv.modTime = info.ModTime()
+	inv.loadedAt = now
+	log.Infof("loaded %d expected nodes from %s", len(nodes), inv.source)
+	return nil
+}
+
+func (inv *Inventory) loadURL(now time.Time) error {
+	resp, err := inv.client.Get(inv.source)
+	if err != nil {
+		return fmt.Errorf("failed to get inventory: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get inventory: unexpected status %s", resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory: %s", err)
+	}
+
+	isCSV := strings.Contains(resp.Header.Get("Content-Type"), "csv") ||
+		strings.HasSuffix(strings.ToLower(resp.Request.URL.Path), ".csv")
+	nodes, err := parseInventory(content, isCSV)
+	if err != nil {
+		return err
+	}
+
+	inv.nodes = nodes
+	inv.loadedAt = now
+	log.Debugf("loaded %d expected nodes from %s", len(nodes), inv.source)
+	return nil
+}
+
+// parseInventory 解析节点清单
+// JSON 格式为 certname 字符串数组或包含 certname 字段的对象数组；
+// CSV 格式使用 certname 列，没有该表头时使用第一列
+func parseInventory(content []byte, isCSV bool) (map[string]string, error) {
+	nodes := make(map[string]string)
+
+	if isCSV {
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse inventory csv: %s", err)
+		}
+		column := 0
+		if len(records) > 0 {
+			for i, field := range records[0] {
+				if strings.EqualFold(strings.TrimSpace(field), "certname") {
+					column = i
+					records = records[1:]
+					break
+				}
+			}
+		}
+		for _, record := range records {
+			if column < len(record) {
+				addInventoryNode(nodes, record[column])
+			}
+		}
+		return nodes, nil
+	}
+
+	var entries []interface{}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal inventory: %s", err)
+	}
+	for _, entry := range entries {
+		switch t := entry.(type) {
+		case string:
+			addInventoryNode(nodes, t)
+		case map[string]interface{}:
+			addInventoryNode(nodes, toString(t["certname"]))
+		}
+	}
+	return nodes, nil
+}
+
+func addInventoryNode(nodes map[string]string, certname string) {
+	certname = strings.TrimSpace(certname)
+	if certname != "" {
+		nodes[strings.ToLower(certname)] = certname
+	}
+}
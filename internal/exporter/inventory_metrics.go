@@ -0,0 +1,93 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// InventoryMetrics 定义期望节点清单对账相关的指标
+type InventoryMetrics struct {
+	loadSuccess   *prometheus.GaugeVec
+	expectedNodes *prometheus.GaugeVec
+	missingCount  *prometheus.GaugeVec
+	unknownCount  *prometheus.GaugeVec
+	missingNode   *prometheus.GaugeVec
+	unknownNode   *prometheus.GaugeVec
+}
+
+// NewInventoryMetrics 创建节点清单指标实例
+func NewInventoryMetrics(namespace string) *InventoryMetrics {
+	im := &InventoryMetrics{}
+
+	im.loadSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_load_success",
+		Help:      "Whether the last load of the expected-node inventory succeeded (1=yes, 0=no).",
+	}, []string{})
+
+	im.expectedNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_expected_nodes",
+		Help:      "Number of nodes listed in the expected-node inventory.",
+	}, []string{})
+
+	im.missingCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_missing_nodes",
+		Help:      "Number of expected nodes that are not active in PuppetDB.",
+	}, []string{})
+
+	im.unknownCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_unknown_nodes",
+		Help:      "Number of active PuppetDB nodes that are not listed in the inventory.",
+	}, []string{})
+
+	im.missingNode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_node_missing",
+		Help:      "Expected node that is not active in PuppetDB (always 1).",
+	}, []string{"host"})
+
+	im.unknownNode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_node_unknown",
+		Help:      "Active PuppetDB node that is not listed in the inventory (always 1).",
+	}, []string{"environment", "host"})
+
+	return im
+}
+
+// Register 注册所有节点清单指标
+func (im *InventoryMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(im.loadSuccess)
+	registerer.MustRegister(im.expectedNodes)
+	registerer.MustRegister(im.missingCount)
+	registerer.MustRegister(im.unknownCount)
+	registerer.MustRegister(im.missingNode)
+	registerer.MustRegister(im.unknownNode)
+}
+
+// UpdateLoadStatus 更新节点清单加载状态
+func (im *InventoryMetrics) UpdateLoadStatus(success bool, expected int) {
+	if success {
+		im.loadSuccess.WithLabelValues().Set(1)
+	} else {
+		im.loadSuccess.WithLabelValues().Set(0)
+	}
+	im.expectedNodes.WithLabelValues().Set(float64(expected))
+}
+
+// UpdateReconciliation 更新对账结果，present 为 PuppetDB 中活跃节点到环境的映射
+func (im *InventoryMetrics) UpdateReconciliation(missing []string, unknown []string, present map[string]string) {
+	im.missingNode.Reset()
+	im.unknownNode.Reset()
+
+	im.missingCount.WithLabelValues().Set(float64(len(missing)))
+	im.unknownCount.WithLabelValues().Set(float64(len(unknown)))
+	for _, certname := range missing {
+		im.missingNode.With(prometheus.Labels{"host": certname}).Set(1)
+	}
+	for _, certname := range unknown {
+		im.unknownNode.With(prometheus.Labels{"environment": present[certname], "host": certname}).Set(1)
+	}
+}
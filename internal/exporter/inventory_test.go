@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInventory(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		isCSV    bool
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "JSON 字符串数组",
+			content:  `["web1.example.com", " Web2.Example.com ", ""]`,
+			expected: map[string]string{"web1.example.com": "web1.example.com", "web2.example.com": "Web2.Example.com"},
+		},
+		{
+			name:     "JSON 对象数组",
+			content:  `[{"certname": "db1", "owner": "dba"}, {"name": "no-certname"}, 42]`,
+			expected: map[string]string{"db1": "db1"},
+		},
+		{
+			name:    "JSON 格式错误",
+			content: `{"certname": "db1"}`,
+			wantErr: true,
+		},
+		{
+			name:     "CSV 带 certname 表头",
+			content:  "owner,Certname\ndba,DB1\nweb,web1\n",
+			isCSV:    true,
+			expected: map[string]string{"db1": "DB1", "web1": "web1"},
+		},
+		{
+			name:     "CSV 没有表头时使用第一列",
+			content:  "web1,production\nweb2,staging\n",
+			isCSV:    true,
+			expected: map[string]string{"web1": "web1", "web2": "web2"},
+		},
+		{
+			name:    "CSV 格式错误",
+			content: "web1,\"unterminated\n",
+			isCSV:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := parseInventory([]byte(tt.content), tt.isCSV)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, nodes)
+		})
+	}
+}
+
+func TestInventoryReconcile(t *testing.T) {
+	inv := &Inventory{nodes: map[string]string{"web1.example.com": "WEB1.example.com", "db1": "db1"}}
+
+	// 比较不区分大小写，返回值保留原始 certname
+	missing, unknown := inv.Reconcile(map[string]string{"Web1.Example.com": "production", "App1": "staging"})
+	assert.Equal(t, []string{"db1"}, missing)
+	assert.Equal(t, []string{"App1"}, unknown)
+}
+
+func TestInventoryLoadURL(t *testing.T) {
+	requests := 0
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("certname\nweb1\nweb2\n"))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	inv := NewInventory(server.URL, 5*time.Minute)
+
+	tests := []struct {
+		name              string
+		at                time.Duration
+		fail              bool
+		expectedAttempted bool
+		expectedErr       bool
+		expectedRequests  int
+	}{
+		{name: "首次加载", at: 0, expectedAttempted: true, expectedRequests: 1},
+		{name: "刷新间隔内不重新获取", at: time.Minute, expectedAttempted: false, expectedRequests: 1},
+		{name: "获取失败", at: 5 * time.Minute, fail: true, expectedAttempted: true, expectedErr: true, expectedRequests: 2},
+		{name: "失败后同样等待刷新间隔", at: 6 * time.Minute, expectedAttempted: false, expectedRequests: 2},
+		{name: "刷新间隔后重试", at: 10 * time.Minute, expectedAttempted: true, expectedRequests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fail = tt.fail
+			attempted, err := inv.Load(now.Add(tt.at))
+			assert.Equal(t, tt.expectedAttempted, attempted)
+			assert.Equal(t, tt.expectedErr, err != nil)
+			assert.Equal(t, tt.expectedRequests, requests)
+
+			// 加载失败时保留上一次成功加载的结果
+			var certnames []string
+			for _, certname := range inv.Nodes() {
+				certnames = append(certnames, certname)
+			}
+			sort.Strings(certnames)
+			assert.Equal(t, []string{"web1", "web2"}, certnames)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/camptocamp/prometheus-puppetdb-exporter/internal/puppetdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JettyMetrics 定义 PuppetDB 内置 Jetty Web 服务器的指标
+// 指标值为 Jetty 的累计快照，因此以常量指标的方式在收集时输出
+type JettyMetrics struct {
+	mu    sync.Mutex
+	stats *puppetdb.JettyStats
+
+	threads          *prometheus.Desc
+	threadsIdle      *prometheus.Desc
+	threadsBusy      *prometheus.Desc
+	threadsMax       *prometheus.Desc
+	threadPoolQueue  *prometheus.Desc
+	openConnections  *prometheus.Desc
+	connectionsTotal *prometheus.Desc
+	receivedBytes    *prometheus.Desc
+	sentBytes        *prometheus.Desc
+	requests         *prometheus.Desc
+	requestsActive   *prometheus.Desc
+	requestTime      *prometheus.Desc
+	responses        *prometheus.Desc
+	responseBytes    *prometheus.Desc
+}
+
+// NewJettyMetrics 创建 Jetty 指标实例
+func NewJettyMetrics(namespace string) *JettyMetrics {
+	desc := func(name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "jetty", name), help, labels, nil)
+	}
+
+	return &JettyMetrics{
+		threads:          desc("threads", "Number of threads in the Jetty thread pool.", "pool"),
+		threadsIdle:      desc("threads_idle", "Number of idle threads in the Jetty thread pool.", "pool"),
+		threadsBusy:      desc("threads_busy", "Number of busy threads in the Jetty thread pool.", "pool"),
+		threadsMax:       desc("threads_max", "Maximum number of threads in the Jetty thread pool.", "pool"),
+		threadPoolQueue:  desc("thread_pool_queue_size", "Number of jobs waiting for a thread in the Jetty thread pool.", "pool"),
+		openConnections:  desc("connector_open_connections", "Number of open connections on the Jetty connector.", "connector"),
+		connectionsTotal: desc("connector_connections_total", "Number of connections opened on the Jetty connector.", "connector"),
+		receivedBytes:    desc("connector_received_bytes_total", "Bytes received by the Jetty connector.", "connector"),
+		sentBytes:        desc("connector_sent_bytes_total", "Bytes sent by the Jetty connector.", "connector"),
+		requests:         desc("requests_total", "Number of requests handled by Jetty, by context.", "context"),
+		requestsActive:   desc("requests_active", "Number of requests currently being handled by Jetty, by context.", "context"),
+		requestTime:      desc("request_time_seconds_total", "Total time spent handling requests in seconds, by context.", "context"),
+		responses:        desc("responses_total", "Number of responses sent by Jetty, by context and status code class.", "context", "code"),
+		responseBytes:    desc("response_bytes_total", "Bytes sent in responses by Jetty, by context.", "context"),
+	}
+}
+
+// Register 注册 Jetty 指标
+func (jm *JettyMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(jm)
+}
+
+// UpdateJettyMetrics 保存最新的 Jetty 统计数据
+func (jm *JettyMetrics) UpdateJettyMetrics(stats *puppetdb.JettyStats) {
+	jm.mu.Lock()
+	jm.stats = stats
+	jm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (jm *JettyMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- jm.threads
+	ch <- jm.threadsIdle
+	ch <- jm.threadsBusy
+	ch <- jm.threadsMax
+	ch <- jm.threadPoolQueue
+	ch <- jm.openConnections
+	ch <- jm.connectionsTotal
+	ch <- jm.receivedBytes
+	ch <- jm.sentBytes
+	ch <- jm.requests
+	ch <- jm.requestsActive
+	ch <- jm.requestTime
+	ch <- jm.responses
+	ch <- jm.responseBytes
+}
+
+// Collect 实现 prometheus.Collector，负值表示 Jetty 未提供该值，不输出
+func (jm *JettyMetrics) Collect(ch chan<- prometheus.Metric) {
+	jm.mu.Lock()
+	stats := jm.stats
+	jm.mu.Unlock()
+
+	if stats == nil {
+		return
+	}
+
+	emit := func(desc *prometheus.Desc, valueType prometheus.ValueType, value float64, labelValues ...string) {
+		if value >= 0 {
+			ch <- prometheus.MustNewConstMetric(desc, valueType, value, labelValues...)
+		}
+	}
+
+	for _, pool := range stats.ThreadPools {
+		emit(jm.threads, prometheus.GaugeValue, pool.Threads, pool.Name)
+		emit(jm.threadsIdle, prometheus.GaugeValue, pool.IdleThreads, pool.Name)
+		emit(jm.threadsBusy, prometheus.GaugeValue, pool.BusyThreads, pool.Name)
+		emit(jm.threadsMax, prometheus.GaugeValue, pool.MaxThreads, pool.Name)
+		emit(jm.threadPoolQueue, prometheus.GaugeValue, pool.QueueSize, pool.Name)
+	}
+	for _, connector := range stats.Connectors {
+		emit(jm.openConnections, prometheus.GaugeValue, connector.OpenConnections, connector.Name)
+		emit(jm.connectionsTotal, prometheus.CounterValue, connector.ConnectionsTotal, connector.Name)
+		emit(jm.receivedBytes, prometheus.CounterValue, connector.ReceivedBytes, connector.Name)
+		emit(jm.sentBytes, prometheus.CounterValue, connector.SentBytes, connector.Name)
+	}
+	for _, context := range stats.Contexts {
+		emit(jm.requests, prometheus.CounterValue, context.Requests, context.Name)
+		emit(jm.requestsActive, prometheus.GaugeValue, context.RequestsActive, context.Name)
+		emit(jm.requestTime, prometheus.CounterValue, context.RequestTime, context.Name)
+		emit(jm.responseBytes, prometheus.CounterValue, context.ResponseBytes, context.Name)
+		for code, count := range context.Responses {
+			emit(jm.responses, prometheus.CounterValue, count, context.Name, code)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LifecycleMetrics 定义已停用和已过期节点的生命周期指标
+type LifecycleMetrics struct {
+	deactivatedAge   *prometheus.GaugeVec
+	expiredAge       *prometheus.GaugeVec
+	purgeIn          *prometheus.GaugeVec
+	approachingPurge *prometheus.GaugeVec
+	nodeChurn        *prometheus.CounterVec
+}
+
+// NewLifecycleMetrics 创建节点生命周期指标实例
+func NewLifecycleMetrics(namespace string) *LifecycleMetrics {
+	lm := &LifecycleMetrics{}
+
+	lm.deactivatedAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_deactivated_age_seconds",
+		Help:      "Time since the node was deactivated in seconds.",
+	}, []string{"environment", "host"})
+
+	lm.expiredAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_expired_age_seconds",
+		Help:      "Time since the node expired in seconds.",
+	}, []string{"environment", "host"})
+
+	lm.purgeIn = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_purge_in_seconds",
+		Help:      "Time left before PuppetDB purges the inactive node according to node-purge-ttl.",
+	}, []string{"environment", "host"})
+
+	lm.approachingPurge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "nodes_approaching_purge",
+		Help:      "Number of inactive nodes that will be purged within the purge warning period.",
+	}, []string{})
+
+	lm.nodeChurn = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "node_churn_total",
+		Help:      "Number of nodes added, deactivated, reactivated or purged between scrapes.",
+	}, []string{"event"})
+
+	return lm
+}
+
+// Register 注册所有节点生命周期指标
+func (lm *LifecycleMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(lm.deactivatedAge)
+	registerer.MustRegister(lm.expiredAge)
+	registerer.MustRegister(lm.purgeIn)
+	registerer.MustRegister(lm.approachingPurge)
+	registerer.MustRegister(lm.nodeChurn)
+}
+
+// Reset 重置按节点导出的生命周期指标
+func (lm *LifecycleMetrics) Reset() {
+	lm.deactivatedAge.Reset()
+	lm.expiredAge.Reset()
+	lm.purgeIn.Reset()
+}
+
+// UpdateInactiveNode 更新已停用或已过期节点的指标，返回节点距离被清理的剩余时间
+// purgeTTL 为 0 时表示 PuppetDB 不清理节点
+func (lm *LifecycleMetrics) UpdateInactiveNode(certname string, environment string, deactivated time.Time, expired time.Time, purgeTTL time.Duration, now time.Time) (time.Duration, bool) {
+	labels := prometheus.Labels{"environment": environment, "host": certname}
+
+	// 以较早的停用或过期时间作为进入非活跃状态的时间
+	var inactiveSince time.Time
+	if !deactivated.IsZero() {
+		lm.deactivatedAge.With(labels).Set(now.Sub(deactivated).Seconds())
+		inactiveSince = deactivated
+	}
+	if !expired.IsZero() {
+		lm.expiredAge.With(labels).Set(now.Sub(expired).Seconds())
+		if inactiveSince.IsZero() || expired.Before(inactiveSince) {
+			inactiveSince = expired
+		}
+	}
+
+	if purgeTTL <= 0 || inactiveSince.IsZero() {
+		return 0, false
+	}
+
+	purgeIn := inactiveSince.Add(purgeTTL).Sub(now)
+	lm.purgeIn.With(labels).Set(purgeIn.Seconds())
+	return purgeIn, true
+}
+
+// UpdateApproachingPurge 更新即将被清理的节点数
+func (lm *LifecycleMetrics) UpdateApproachingPurge(count int) {
+	lm.approachingPurge.WithLabelValues().Set(float64(count))
+}
+
+// RecordChurn 记录两次抓取之间的节点变动
+func (lm *LifecycleMetrics) RecordChurn(events map[string]int) {
+	for _, event := range []string{nodeEventAdded, nodeEventDeactivated, nodeEventReactivated, nodeEventPurged} {
+		lm.nodeChurn.With(prometheus.Labels{"event": event}).Add(float64(events[event]))
+	}
+}
@@ -0,0 +1,230 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaintenanceWindow 维护窗口，Certname 支持 path.Match 通配符
+// Start/End 为零值时表示不限制开始/结束时间
+type MaintenanceWindow struct {
+	Certname    string    `json:"certname"`
+	Environment string    `json:"environment,omitempty"`
+	Start       time.Time `json:"start,omitempty"`
+	End         time.Time `json:"end,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Source      string    `json:"source"`
+}
+
+// Maintenance 汇总维护窗口来源：计划文件、维护事实和本地 HTTP API
+type Maintenance struct {
+	mu          sync.Mutex
+	file        string
+	fileModTime time.Time
+	fileWindows []MaintenanceWindow
+	apiWindows  []MaintenanceWindow
+	facts       map[string]bool
+}
+
+// NewMaintenance 创建维护窗口管理器，file 为空时不加载计划文件
+func NewMaintenance(file string) *Maintenance {
+	return &Maintenance{
+		file:  file,
+		facts: make(map[string]bool),
+	}
+}
+
+// Reload 在计划文件发生变化时重新加载
+func (m *Maintenance) Reload() error {
+	if m.file == "" {
+		return nil
+	}
+
+	info, err := os.Stat(m.file)
+	if err != nil {
+		return fmt.Errorf("failed to stat maintenance file: %s", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if info.ModTime().Equal(m.fileModTime) {
+		return nil
+	}
+
+	content, err := os.ReadFile(m.file)
+	if err != nil {
+		return fmt.Errorf("failed to read maintenance file: %s", err)
+	}
+	var windows []MaintenanceWindow
+	if err := json.Unmarshal(content, &windows); err != nil {
+		return fmt.Errorf("failed to unmarshal maintenance file: %s", err)
+	}
+	for i := range windows {
+		windows[i].Source = "file"
+	}
+
+	m.fileWindows = windows
+	m.fileModTime = info.ModTime()
+	log.Infof("loaded %d maintenance windows from %s", len(windows), m.file)
+	return nil
+}
+
+// SetFacts 更新维护事实的取值
+func (m *Maintenance) SetFacts(facts map[string]interface{}) {
+	values := make(map[string]bool, len(facts))
+	for certname, value := range facts {
+		if boolToFloat(value) == 1 || value == "yes" {
+			values[certname] = true
+		}
+	}
+
+	m.mu.Lock()
+	m.facts = values
+	m.mu.Unlock()
+}
+
+// Add 注册一个维护窗口
+func (m *Maintenance) Add(window MaintenanceWindow) {
+	window.Source = "api"
+
+	m.mu.Lock()
+	m.apiWindows = append(m.apiWindows, window)
+	m.mu.Unlock()
+}
+
+// Remove 删除通过 API 注册的匹配维护窗口，返回删除的数量
+func (m *Maintenance) Remove(certname string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.apiWindows[:0]
+	for _, window := range m.apiWindows {
+		if window.Certname != certname {
+			kept = append(kept, window)
+		}
+	}
+	removed := len(m.apiWindows) - len(kept)
+	m.apiWindows = kept
+	return removed
+}
+
+// Windows 返回当前及未来的维护窗口，并清理已过期的 API 窗口
+func (m *Maintenance) Windows(now time.Time) []MaintenanceWindow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.apiWindows[:0]
+	for _, window := range m.apiWindows {
+		if window.End.IsZero() || window.End.After(now) {
+			kept = append(kept, window)
+		}
+	}
+	m.apiWindows = kept
+
+	windows := make([]MaintenanceWindow, 0, len(m.fileWindows)+len(m.apiWindows))
+	for _, source := range [][]MaintenanceWindow{m.fileWindows, m.apiWindows} {
+		for _, window := range source {
+			if window.End.IsZero() || window.End.After(now) {
+				windows = append(windows, window)
+			}
+		}
+	}
+	return windows
+}
+
+// InMaintenance 判断节点当前是否处于维护状态
+func (m *Maintenance) InMaintenance(certname string, environment string, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.facts[certname] {
+		return true
+	}
+	// 分别遍历两个来源，append 合并会写入 fileWindows 的剩余容量
+	for _, source := range [][]MaintenanceWindow{m.fileWindows, m.apiWindows} {
+		for _, window := range source {
+			if window.matches(certname, environment, now) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w MaintenanceWindow) matches(certname string, environment string, now time.Time) bool {
+	if !w.Start.IsZero() && now.Before(w.Start) {
+		return false
+	}
+	if !w.End.IsZero() && !now.Before(w.End) {
+		return false
+	}
+	if w.Environment != "" && w.Environment != environment {
+		return false
+	}
+	if w.Certname == "" {
+		return w.Environment != ""
+	}
+	matched, err := path.Match(w.Certname, certname)
+	return err == nil && matched
+}
+
+// maintenanceRequest 维护窗口 API 请求体，Duration 可替代 End
+type maintenanceRequest struct {
+	MaintenanceWindow
+	Duration string `json:"duration,omitempty"`
+}
+
+// ServeHTTP 提供维护窗口 API：GET 列出，POST 注册，DELETE ?certname= 删除
+func (m *Maintenance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.Windows(now))
+	case http.MethodPost:
+		var req maintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+			return
+		}
+		if req.Certname == "" && req.Environment == "" {
+			http.Error(w, "certname or environment is required", http.StatusBadRequest)
+			return
+		}
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid duration: %s", err), http.StatusBadRequest)
+				return
+			}
+			start := req.Start
+			if start.IsZero() {
+				start = now
+			}
+			req.End = start.Add(d)
+		}
+		m.Add(req.MaintenanceWindow)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		certname := r.URL.Query().Get("certname")
+		if certname == "" {
+			http.Error(w, "certname is required", http.StatusBadRequest)
+			return
+		}
+		if m.Remove(certname) == 0 {
+			http.Error(w, "no matching maintenance window", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
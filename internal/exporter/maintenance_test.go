@@ -0,0 +1,110 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceWindowMatches(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		window      MaintenanceWindow
+		certname    string
+		environment string
+		expected    bool
+	}{
+		{name: "精确匹配", window: MaintenanceWindow{Certname: "web1.example.com"}, certname: "web1.example.com", expected: true},
+		{name: "通配符", window: MaintenanceWindow{Certname: "web*.example.com"}, certname: "web2.example.com", expected: true},
+		{name: "通配符不匹配", window: MaintenanceWindow{Certname: "web*.example.com"}, certname: "db1.example.com", expected: false},
+		{name: "无效模式", window: MaintenanceWindow{Certname: "web[.example.com"}, certname: "web[.example.com", expected: false},
+		{name: "限定环境", window: MaintenanceWindow{Certname: "web1", Environment: "production"}, certname: "web1", environment: "staging", expected: false},
+		{name: "整个环境", window: MaintenanceWindow{Environment: "staging"}, certname: "web1", environment: "staging", expected: true},
+		{name: "未指定节点和环境", window: MaintenanceWindow{}, certname: "web1", environment: "staging", expected: false},
+		{name: "尚未开始", window: MaintenanceWindow{Certname: "web1", Start: now.Add(time.Minute)}, certname: "web1", expected: false},
+		{name: "已经开始", window: MaintenanceWindow{Certname: "web1", Start: now, End: now.Add(time.Hour)}, certname: "web1", expected: true},
+		{name: "结束时间不包含在内", window: MaintenanceWindow{Certname: "web1", End: now}, certname: "web1", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.window.matches(tt.certname, tt.environment, now))
+		})
+	}
+}
+
+func TestMaintenanceInMaintenance(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMaintenance("")
+	m.fileWindows = []MaintenanceWindow{{Certname: "db*", End: now.Add(time.Hour), Source: "file"}}
+	m.Add(MaintenanceWindow{Certname: "web1", End: now.Add(time.Hour)})
+	m.SetFacts(map[string]interface{}{"app1": true, "app2": "yes", "app3": false})
+
+	tests := []struct {
+		name     string
+		certname string
+		at       time.Time
+		expected bool
+	}{
+		{name: "计划文件", certname: "db1", at: now, expected: true},
+		{name: "API", certname: "web1", at: now, expected: true},
+		{name: "布尔事实", certname: "app1", at: now, expected: true},
+		{name: "字符串事实", certname: "app2", at: now, expected: true},
+		{name: "事实为 false", certname: "app3", at: now, expected: false},
+		{name: "窗口结束后", certname: "web1", at: now.Add(time.Hour), expected: false},
+		{name: "事实不受时间限制", certname: "app1", at: now.Add(time.Hour), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, m.InMaintenance(tt.certname, "production", tt.at))
+		})
+	}
+}
+
+func TestMaintenanceWindowsExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMaintenance("")
+	m.fileWindows = []MaintenanceWindow{
+		{Certname: "db1", End: now.Add(-time.Minute), Source: "file"},
+		{Certname: "db2", Source: "file"},
+	}
+	m.Add(MaintenanceWindow{Certname: "web1", End: now})
+	m.Add(MaintenanceWindow{Certname: "web2", End: now.Add(time.Hour)})
+	m.Add(MaintenanceWindow{Certname: "web3", Start: now.Add(time.Hour)})
+
+	var certnames []string
+	for _, window := range m.Windows(now) {
+		certnames = append(certnames, window.Certname)
+	}
+	// 已结束的窗口不再列出，未来的窗口保留
+	assert.Equal(t, []string{"db2", "web2", "web3"}, certnames)
+
+	// 过期的 API 窗口被清理，计划文件的窗口保留到文件重新加载
+	assert.Len(t, m.apiWindows, 2)
+	assert.Len(t, m.fileWindows, 2)
+	assert.Equal(t, 0, m.Remove("web1"))
+	assert.Equal(t, 1, m.Remove("web2"))
+}
+
+func TestMaintenanceReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`[{"certname": "web*", "reason": "upgrade"}]`), 0644))
+
+	m := NewMaintenance(file)
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, []MaintenanceWindow{{Certname: "web*", Reason: "upgrade", Source: "file"}}, m.Windows(time.Now()))
+
+	assert.NoError(t, os.WriteFile(file, []byte(`not json`), 0644))
+	assert.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	assert.Error(t, m.Reload())
+	// 加载失败时保留上一次的窗口
+	assert.Len(t, m.Windows(time.Now()), 1)
+}
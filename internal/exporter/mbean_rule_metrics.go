@@ -0,0 +1,140 @@
+package exporter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// mbeanRuleValueTypes 规则类型到 Prometheus 值类型的映射
+var mbeanRuleValueTypes = map[string]prometheus.ValueType{
+	"gauge":   prometheus.GaugeValue,
+	"counter": prometheus.CounterValue,
+	"untyped": prometheus.UntypedValue,
+}
+
+// MBeanRuleMetrics 输出 MBean 规则生成的指标
+// 指标名称和标签由规则在运行时决定，因此在收集时动态创建指标描述
+type MBeanRuleMetrics struct {
+	mu      sync.Mutex
+	samples []MBeanRuleSample
+	matched map[string]int
+
+	loadSuccess prometheus.Gauge
+	matchedDesc *prometheus.Desc
+	samplesDesc *prometheus.Desc
+}
+
+// NewMBeanRuleMetrics 创建 MBean 规则指标实例
+func NewMBeanRuleMetrics(namespace string) *MBeanRuleMetrics {
+	return &MBeanRuleMetrics{
+		loadSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mbean_rules_load_success",
+			Help:      "Whether the last load of the MBean rules file succeeded (1=yes, 0=no).",
+		}),
+		matchedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "mbean_rule_matched_mbeans"),
+			"Number of MBeans matched by the rule with the given pattern.",
+			[]string{"pattern"}, nil,
+		),
+		samplesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "mbean_rule_samples"),
+			"Number of metrics generated by MBean rules in the last scrape.",
+			nil, nil,
+		),
+	}
+}
+
+// Register 注册 MBean 规则指标
+func (rm *MBeanRuleMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(rm)
+}
+
+// UpdateLoadSuccess 更新规则文件加载状态
+func (rm *MBeanRuleMetrics) UpdateLoadSuccess(success bool) {
+	rm.loadSuccess.Set(boolToFloat(success))
+}
+
+// UpdateSamples 保存最新的规则求值结果
+func (rm *MBeanRuleMetrics) UpdateSamples(samples []MBeanRuleSample) {
+	matched := make(map[string]int)
+	for _, sample := range samples {
+		matched[sample.Rule]++
+	}
+
+	rm.mu.Lock()
+	rm.samples = samples
+	rm.matched = matched
+	rm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector，规则生成的指标不在此描述
+func (rm *MBeanRuleMetrics) Describe(ch chan<- *prometheus.Desc) {
+	rm.loadSuccess.Describe(ch)
+	ch <- rm.matchedDesc
+	ch <- rm.samplesDesc
+}
+
+// Collect 实现 prometheus.Collector
+// 同名指标的标签必须一致，冲突或重复的样本会被丢弃，以免整个抓取失败
+func (rm *MBeanRuleMetrics) Collect(ch chan<- prometheus.Metric) {
+	rm.mu.Lock()
+	samples := rm.samples
+	matched := rm.matched
+	rm.mu.Unlock()
+
+	rm.loadSuccess.Collect(ch)
+	for pattern, count := range matched {
+		ch <- prometheus.MustNewConstMetric(rm.matchedDesc, prometheus.GaugeValue, float64(count), pattern)
+	}
+
+	families := make(map[string]string)
+	seen := make(map[string]bool)
+	emitted := 0
+	for _, sample := range samples {
+		labelNames := make([]string, 0, len(sample.Labels))
+		for name := range sample.Labels {
+			labelNames = append(labelNames, name)
+		}
+		sort.Strings(labelNames)
+		labelValues := make([]string, len(labelNames))
+		for i, name := range labelNames {
+			labelValues[i] = sample.Labels[name]
+		}
+
+		signature := sample.Type + "|" + strings.Join(labelNames, ",")
+		if existing, ok := families[sample.Name]; ok && existing != signature {
+			log.Debugf("skipping mbean rule metric %s: inconsistent type or labels", sample.Name)
+			continue
+		}
+		key := sample.Name + "|" + strings.Join(labelValues, "\xff")
+		if seen[key] {
+			log.Debugf("skipping duplicate mbean rule metric %s%v", sample.Name, sample.Labels)
+			continue
+		}
+		families[sample.Name] = signature
+		seen[key] = true
+
+		if sample.Type == "dropwizard" {
+			descs := newDropwizardDescs(DropwizardOpts{Name: sample.Name, Help: sample.Help}, labelNames)
+			descs.collect(ch, sample.Scale, sample.Data, labelValues)
+			emitted++
+			continue
+		}
+
+		desc := prometheus.NewDesc(sample.Name, sample.Help, labelNames, nil)
+		metric, err := prometheus.NewConstMetric(desc, mbeanRuleValueTypes[sample.Type], sample.Value, labelValues...)
+		if err != nil {
+			log.Debugf("skipping mbean rule metric %s: %s", sample.Name, err)
+			continue
+		}
+		ch <- metric
+		emitted++
+	}
+
+	ch <- prometheus.MustNewConstMetric(rm.samplesDesc, prometheus.GaugeValue, float64(emitted))
+}
@@ -0,0 +1,284 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// mbeanRuleTypes 规则支持的指标类型
+var mbeanRuleTypes = map[string]bool{
+	"gauge":      true,
+	"counter":    true,
+	"untyped":    true,
+	"dropwizard": true,
+}
+
+// numericGroupRef 模板中的数字捕获组引用，例如 $1_seconds 中的 $1
+var numericGroupRef = regexp.MustCompile(`\$(\d+)`)
+
+// invalidMetricNameChars 指标名称和标签名称中不允许的字符
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// metricNamePrefix 合法的指标名称前缀
+var metricNamePrefix = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// MBeanRule 将匹配的 MBean 映射为指标，类似 jmx_exporter 的规则
+// Name、Help 和 Labels 中可以使用 Pattern 的捕获组（$1、${name}）
+type MBeanRule struct {
+	// Pattern 匹配完整 MBean 名称的正则表达式
+	Pattern string `json:"pattern"`
+	// Attribute 读取的属性，支持用 "." 访问复合属性（如 HeapMemoryUsage.used），
+	// 为空时依次尝试 Value 和 Count；dropwizard 类型忽略此字段
+	Attribute string            `json:"attribute,omitempty"`
+	Name      string            `json:"name"`
+	Help      string            `json:"help,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Type 为 gauge、counter、untyped 或 dropwizard，默认 gauge
+	Type string `json:"type,omitempty"`
+	// Scale 值的换算系数，默认 1
+	Scale float64 `json:"scale,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// MBeanRuleSample 规则对单个 MBean 求值的结果
+type MBeanRuleSample struct {
+	Rule   string
+	Name   string
+	Help   string
+	Type   string
+	Labels map[string]string
+	Value  float64
+	Scale  float64
+	// Data dropwizard 类型的完整 MBean 数据
+	Data map[string]interface{}
+}
+
+// MBeanRules 从 JSON 文件加载的 MBean 映射规则，文件变化时重新加载
+// 规则生成的指标名称统一加上 prefix，避免与导出器自身的指标重名
+type MBeanRules struct {
+	mu      sync.Mutex
+	file    string
+	prefix  string
+	modTime time.Time
+	rules   []MBeanRule
+}
+
+// NewMBeanRules 创建 MBean 规则集，file 为空时不加载规则
+func NewMBeanRules(file string, prefix string) (*MBeanRules, error) {
+	if file != "" && !metricNamePrefix.MatchString(prefix) {
+		return nil, fmt.Errorf("invalid mbean rules prefix %q, must be a valid metric name", prefix)
+	}
+	return &MBeanRules{file: file, prefix: prefix}, nil
+}
+
+// Enabled 是否配置了规则文件
+func (mr *MBeanRules) Enabled() bool {
+	return mr.file != ""
+}
+
+// Reload 在规则文件发生变化时重新加载，加载失败时保留原有规则
+func (mr *MBeanRules) Reload() error {
+	if mr.file == "" {
+		return nil
+	}
+
+	info, err := os.Stat(mr.file)
+	if err != nil {
+		return fmt.Errorf("failed to stat mbean rules file: %s", err)
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	if info.ModTime().Equal(mr.modTime) {
+		return nil
+	}
+
+	content, err := os.ReadFile(mr.file)
+	if err != nil {
+		return fmt.Errorf("failed to read mbean rules file: %s", err)
+	}
+	var rules []MBeanRule
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return fmt.Errorf("failed to unmarshal mbean rules file: %s", err)
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return fmt.Errorf("invalid mbean rule %d: %s", i, err)
+		}
+	}
+
+	mr.rules = rules
+	mr.modTime = info.ModTime()
+	log.Infof("loaded %d mbean rules from %s", len(rules), mr.file)
+	return nil
+}
+
+func (r *MBeanRule) compile() (err error) {
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Type == "" {
+		r.Type = "gauge"
+	}
+	if !mbeanRuleTypes[r.Type] {
+		return fmt.Errorf("unknown type %q", r.Type)
+	}
+	if r.Scale == 0 {
+		r.Scale = 1
+	}
+	labels := make(map[string]string, len(r.Labels))
+	for name, template := range r.Labels {
+		name = sanitizeLabelName(name)
+		if name == "" || strings.HasPrefix(name, "__") || (r.Type == "dropwizard" && name == "window") {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		labels[name] = template
+	}
+	r.Labels = labels
+	r.re, err = regexp.Compile("^(?:" + r.Pattern + ")$")
+	if err != nil {
+		return fmt.Errorf("failed to compile pattern: %s", err)
+	}
+	return nil
+}
+
+// Match 返回至少被一条规则匹配的 MBean
+func (mr *MBeanRules) Match(mbeans []string) []string {
+	mr.mu.Lock()
+	rules := mr.rules
+	mr.mu.Unlock()
+
+	var matched []string
+	for _, mbean := range mbeans {
+		for _, rule := range rules {
+			if rule.re.MatchString(mbean) {
+				matched = append(matched, mbean)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Evaluate 对读取到的 MBean 数据求值，指标名称加上规则集的前缀
+// 同一 MBean 的同一属性只由第一条匹配的规则处理
+func (mr *MBeanRules) Evaluate(data map[string]map[string]interface{}) []MBeanRuleSample {
+	mr.mu.Lock()
+	rules := mr.rules
+	mr.mu.Unlock()
+
+	var samples []MBeanRuleSample
+	for mbean, value := range data {
+		handled := make(map[string]bool)
+		for _, rule := range rules {
+			match := rule.re.FindStringSubmatchIndex(mbean)
+			if match == nil || handled[rule.Attribute] {
+				continue
+			}
+
+			sample, ok := rule.evaluate(mbean, match, value)
+			if !ok {
+				continue
+			}
+			handled[rule.Attribute] = true
+			sample.Name = mr.prefix + sample.Name
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+func (r MBeanRule) evaluate(mbean string, match []int, data map[string]interface{}) (MBeanRuleSample, bool) {
+	expand := func(template string) string {
+		// 与 jmx_exporter 一致，$1 后面紧跟的字母和下划线不属于组名
+		template = numericGroupRef.ReplaceAllString(template, "$${${1}}")
+		return string(r.re.ExpandString(nil, template, mbean, match))
+	}
+
+	sample := MBeanRuleSample{
+		Rule:   r.Pattern,
+		Name:   sanitizeMetricName(expand(r.Name)),
+		Help:   expand(r.Help),
+		Type:   r.Type,
+		Labels: make(map[string]string, len(r.Labels)),
+		Scale:  r.Scale,
+	}
+	if sample.Name == "" {
+		return sample, false
+	}
+	if sample.Help == "" {
+		sample.Help = "Value of MBean attribute matched by " + r.Pattern + "."
+	}
+	for name, template := range r.Labels {
+		sample.Labels[name] = expand(template)
+	}
+
+	if r.Type == "dropwizard" {
+		sample.Data = data
+		return sample, true
+	}
+
+	value, ok := mbeanAttribute(data, r.Attribute)
+	if !ok {
+		return sample, false
+	}
+	sample.Value = value * r.Scale
+	return sample, true
+}
+
+// mbeanAttribute 读取 MBean 数据中的属性，支持用 "." 访问复合属性
+func mbeanAttribute(data map[string]interface{}, attribute string) (float64, bool) {
+	if attribute == "" {
+		for _, field := range []string{"Value", "Count"} {
+			if value, ok := mbeanAttribute(data, field); ok {
+				return value, true
+			}
+		}
+		return 0, false
+	}
+
+	var current interface{} = data
+	for _, part := range strings.Split(attribute, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		if current, ok = m[part]; !ok {
+			return 0, false
+		}
+	}
+
+	switch v := current.(type) {
+	case float64:
+		return v, true
+	case bool:
+		return boolToFloat(v), true
+	default:
+		return 0, false
+	}
+}
+
+// sanitizeMetricName 将不合法的字符替换为下划线
+func sanitizeMetricName(name string) string {
+	name = invalidMetricNameChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// sanitizeLabelName 将标签名称中不合法的字符替换为下划线
+func sanitizeLabelName(name string) string {
+	return strings.ReplaceAll(sanitizeMetricName(name), ":", "_")
+}
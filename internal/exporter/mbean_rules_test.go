@@ -0,0 +1,188 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMBeanRuleCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     MBeanRule
+		expected MBeanRule
+		wantErr  bool
+	}{
+		{
+			name:     "默认类型和换算系数",
+			rule:     MBeanRule{Pattern: "a:name=(.+)", Name: "a_$1"},
+			expected: MBeanRule{Pattern: "a:name=(.+)", Name: "a_$1", Type: "gauge", Scale: 1, Labels: map[string]string{}},
+		},
+		{
+			name:     "标签名称中的不合法字符替换为下划线",
+			rule:     MBeanRule{Pattern: "a", Name: "a", Type: "counter", Scale: 2, Labels: map[string]string{"pool-name": "$1", "a:b": "x"}},
+			expected: MBeanRule{Pattern: "a", Name: "a", Type: "counter", Scale: 2, Labels: map[string]string{"pool_name": "$1", "a_b": "x"}},
+		},
+		{name: "缺少 pattern", rule: MBeanRule{Name: "a"}, wantErr: true},
+		{name: "缺少 name", rule: MBeanRule{Pattern: "a"}, wantErr: true},
+		{name: "未知类型", rule: MBeanRule{Pattern: "a", Name: "a", Type: "histogram"}, wantErr: true},
+		{name: "保留的标签名称", rule: MBeanRule{Pattern: "a", Name: "a", Labels: map[string]string{"__name__": "x"}}, wantErr: true},
+		{name: "dropwizard 的 window 标签", rule: MBeanRule{Pattern: "a", Name: "a", Type: "dropwizard", Labels: map[string]string{"window": "x"}}, wantErr: true},
+		{name: "无效的正则表达式", rule: MBeanRule{Pattern: "a(", Name: "a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.compile()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, tt.rule.re)
+			tt.rule.re = nil
+			assert.Equal(t, tt.expected, tt.rule)
+		})
+	}
+}
+
+func TestMBeanRuleEvaluate(t *testing.T) {
+	const mbean = "puppetlabs.puppetdb.storage:name=gc-time"
+	data := map[string]interface{}{
+		"Count": float64(4),
+		"Mean":  float64(20),
+		"Usage": map[string]interface{}{"used": float64(3), "enabled": true},
+	}
+
+	tests := []struct {
+		name           string
+		rule           MBeanRule
+		expectedOK     bool
+		expectedName   string
+		expectedHelp   string
+		expectedLabels map[string]string
+		expectedValue  float64
+	}{
+		{
+			// 与 jmx_exporter 一致，$1 后面的 _seconds 不属于组名
+			name:           "数字组引用后跟后缀",
+			rule:           MBeanRule{Pattern: `puppetlabs\.puppetdb\.storage:name=(.+)-time`, Name: "storage_$1_seconds"},
+			expectedOK:     true,
+			expectedName:   "storage_gc_seconds",
+			expectedHelp:   `Value of MBean attribute matched by puppetlabs\.puppetdb\.storage:name=(.+)-time.`,
+			expectedLabels: map[string]string{},
+			expectedValue:  4,
+		},
+		{
+			name:           "命名组和标签",
+			rule:           MBeanRule{Pattern: `(?P<domain>[^:]+):name=(?P<metric>.+)`, Name: "${domain}_value", Help: "Metric ${metric}.", Labels: map[string]string{"metric": "$2"}, Scale: 0.5},
+			expectedOK:     true,
+			expectedName:   "puppetlabs_puppetdb_storage_value",
+			expectedHelp:   "Metric gc-time.",
+			expectedLabels: map[string]string{"metric": "gc-time"},
+			expectedValue:  2,
+		},
+		{
+			name:           "复合属性",
+			rule:           MBeanRule{Pattern: ".+", Name: "usage_used", Attribute: "Usage.used"},
+			expectedOK:     true,
+			expectedName:   "usage_used",
+			expectedHelp:   "Value of MBean attribute matched by .+.",
+			expectedLabels: map[string]string{},
+			expectedValue:  3,
+		},
+		{
+			name:           "布尔属性",
+			rule:           MBeanRule{Pattern: ".+", Name: "usage_enabled", Attribute: "Usage.enabled"},
+			expectedOK:     true,
+			expectedName:   "usage_enabled",
+			expectedHelp:   "Value of MBean attribute matched by .+.",
+			expectedLabels: map[string]string{},
+			expectedValue:  1,
+		},
+		{
+			name: "缺少属性",
+			rule: MBeanRule{Pattern: ".+", Name: "missing", Attribute: "Usage.max"},
+		},
+		{
+			name: "名称展开为空",
+			rule: MBeanRule{Pattern: `.+:name=(x)?.+`, Name: "$1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.rule.compile())
+			sample, ok := tt.rule.evaluate(mbean, tt.rule.re.FindStringSubmatchIndex(mbean), data)
+			assert.Equal(t, tt.expectedOK, ok)
+			if !tt.expectedOK {
+				return
+			}
+			assert.Equal(t, tt.expectedName, sample.Name)
+			assert.Equal(t, tt.expectedHelp, sample.Help)
+			assert.Equal(t, tt.expectedLabels, sample.Labels)
+			assert.Equal(t, tt.expectedValue, sample.Value)
+		})
+	}
+}
+
+func TestMBeanRulesEvaluate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`[
+		{"pattern": "puppetlabs.puppetdb.population:name=num-(.+)", "name": "population_$1"},
+		{"pattern": "puppetlabs.puppetdb.population:name=(.+)", "name": "population_value", "labels": {"metric": "$1"}},
+		{"pattern": "puppetlabs.puppetdb.storage:name=(.+)-time", "name": "storage_$1_seconds", "type": "dropwizard"}
+	]`), 0644))
+
+	rules, err := NewMBeanRules(file, "puppetdb_jmx_")
+	assert.NoError(t, err)
+	assert.NoError(t, rules.Reload())
+
+	assert.ElementsMatch(t, []string{
+		"puppetlabs.puppetdb.population:name=num-nodes",
+		"puppetlabs.puppetdb.storage:name=gc-time",
+	}, rules.Match([]string{
+		"puppetlabs.puppetdb.population:name=num-nodes",
+		"puppetlabs.puppetdb.storage:name=gc-time",
+		"puppetlabs.puppetdb.storage:name=replace-facts",
+	}))
+
+	samples := rules.Evaluate(map[string]map[string]interface{}{
+		"puppetlabs.puppetdb.population:name=num-nodes": {"Value": float64(12)},
+		"puppetlabs.puppetdb.storage:name=gc-time":      {"Count": float64(1), "Mean": float64(5)},
+	})
+	names := make(map[string]float64)
+	for _, sample := range samples {
+		names[sample.Name] = sample.Value
+	}
+	// 规则指标带有前缀，同一属性只由第一条匹配的规则处理
+	assert.Equal(t, map[string]float64{
+		"puppetdb_jmx_population_nodes":   12,
+		"puppetdb_jmx_storage_gc_seconds": 0,
+	}, names)
+}
+
+func TestNewMBeanRulesPrefix(t *testing.T) {
+	tests := []struct {
+		file    string
+		prefix  string
+		wantErr bool
+	}{
+		{file: "rules.json", prefix: "puppetdb_jmx_"},
+		{file: "rules.json", prefix: "custom:"},
+		{file: "rules.json", prefix: "", wantErr: true},
+		{file: "rules.json", prefix: "1puppetdb_", wantErr: true},
+		{file: "rules.json", prefix: "puppetdb-jmx_", wantErr: true},
+		// 没有规则文件时不检查前缀
+		{file: "", prefix: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			_, err := NewMBeanRules(tt.file, tt.prefix)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetaMetrics 定义 PuppetDB 元数据（/pdb/meta/v1）相关的指标
+type MetaMetrics struct {
+	versionInfo    *prometheus.GaugeVec
+	clockSkew      prometheus.Gauge
+	metricsBackend *prometheus.GaugeVec
+}
+
+// NewMetaMetrics 创建元数据指标实例
+func NewMetaMetrics(namespace string) *MetaMetrics {
+	mm := &MetaMetrics{}
+
+	mm.versionInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "version_info",
+		Help:      "PuppetDB version reported by /pdb/meta/v1/version (always 1).",
+	}, []string{"version"})
+
+	mm.clockSkew = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "clock_skew_seconds",
+		Help:      "Difference between the PuppetDB server time and the exporter time in seconds (positive when PuppetDB is ahead).",
+	})
+
+	mm.metricsBackend = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "metrics_backend",
+		Help:      "Metrics API used to read PuppetDB MBeans: v2 (/metrics/v2, Jolokia), v1 (/metrics/v1/mbeans) or none (always 1).",
+	}, []string{"backend"})
+
+	return mm
+}
+
+// Register 注册所有元数据指标
+func (mm *MetaMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(mm.versionInfo)
+	registerer.MustRegister(mm.clockSkew)
+	registerer.MustRegister(mm.metricsBackend)
+}
+
+// UpdateVersion 更新 PuppetDB 版本，升级后只保留新版本
+func (mm *MetaMetrics) UpdateVersion(version string) {
+	mm.versionInfo.Reset()
+	mm.versionInfo.WithLabelValues(version).Set(1)
+}
+
+// UpdateClockSkew 更新 PuppetDB 与导出器之间的时钟偏差
+func (mm *MetaMetrics) UpdateClockSkew(skew float64) {
+	mm.clockSkew.Set(skew)
+}
+
+// UpdateMetricsBackend 更新当前使用的指标 API
+func (mm *MetaMetrics) UpdateMetricsBackend(backend string) {
+	mm.metricsBackend.Reset()
+	mm.metricsBackend.WithLabelValues(backend).Set(1)
+}
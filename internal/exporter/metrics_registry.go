@@ -4,34 +4,76 @@ import "github.com/prometheus/client_golang/prometheus"
 
 // MetricsRegistry 指标注册表，统一管理所有指标
 type MetricsRegistry struct {
-	nodeMetrics        *NodeMetrics
-	serviceMetrics     *ServiceMetrics
-	systemMetrics      *SystemMetrics
-	metricsV2          *MetricsV2
-	performanceMetrics *PerformanceMetrics
-	puppetDBMetrics    *PuppetDBMetrics
+	nodeMetrics         *NodeMetrics
+	serviceMetrics      *ServiceMetrics
+	systemMetrics       *SystemMetrics
+	metricsV2           *MetricsV2
+	performanceMetrics  *PerformanceMetrics
+	puppetDBMetrics     *PuppetDBMetrics
+	lifecycleMetrics    *LifecycleMetrics
+	inventoryMetrics    *InventoryMetrics
+	caMetrics           *CAMetrics
+	puppetServerMetrics *PuppetServerMetrics
+	commandMetrics      *CommandMetrics
+	mbeanRuleMetrics    *MBeanRuleMetrics
+	adminMetrics        *AdminMetrics
+	storageMetrics      *StorageMetrics
+	dloMetrics          *DLOMetrics
+	jettyMetrics        *JettyMetrics
+	jvmThreadMetrics    *JVMThreadMetrics
+	summaryStatsMetrics *SummaryStatsMetrics
+	metaMetrics         *MetaMetrics
+	apiMetrics          *APIMetrics
 }
 
 // NewMetricsRegistry 创建指标注册表
 func NewMetricsRegistry(namespace string, categories map[string]struct{}) *MetricsRegistry {
 	return &MetricsRegistry{
-		nodeMetrics:        NewNodeMetrics(namespace, categories),
-		serviceMetrics:     NewServiceMetrics(namespace),
-		systemMetrics:      NewSystemMetrics(namespace),
-		metricsV2:          NewMetricsV2(namespace),
-		performanceMetrics: NewPerformanceMetrics(namespace),
-		puppetDBMetrics:    NewPuppetDBMetrics(namespace),
+		nodeMetrics:         NewNodeMetrics(namespace, categories),
+		serviceMetrics:      NewServiceMetrics(namespace),
+		systemMetrics:       NewSystemMetrics(namespace),
+		metricsV2:           NewMetricsV2(namespace),
+		performanceMetrics:  NewPerformanceMetrics(namespace),
+		puppetDBMetrics:     NewPuppetDBMetrics(namespace),
+		lifecycleMetrics:    NewLifecycleMetrics(namespace),
+		inventoryMetrics:    NewInventoryMetrics(namespace),
+		caMetrics:           NewCAMetrics(namespace),
+		puppetServerMetrics: NewPuppetServerMetrics("puppetserver"),
+		commandMetrics:      NewCommandMetrics(namespace),
+		mbeanRuleMetrics:    NewMBeanRuleMetrics(namespace),
+		adminMetrics:        NewAdminMetrics(namespace),
+		storageMetrics:      NewStorageMetrics(namespace),
+		dloMetrics:          NewDLOMetrics(namespace),
+		jettyMetrics:        NewJettyMetrics(namespace),
+		jvmThreadMetrics:    NewJVMThreadMetrics(namespace),
+		summaryStatsMetrics: NewSummaryStatsMetrics(namespace),
+		metaMetrics:         NewMetaMetrics(namespace),
+		apiMetrics:          NewAPIMetrics(namespace),
 	}
 }
 
 // RegisterAll 注册所有指标
-func (mr *MetricsRegistry) RegisterAll() {
-	mr.nodeMetrics.Register()
-	mr.serviceMetrics.Register()
-	mr.systemMetrics.Register()
-	mr.metricsV2.Register()
-	mr.performanceMetrics.Register()
-	mr.puppetDBMetrics.Register()
+func (mr *MetricsRegistry) RegisterAll(registerer prometheus.Registerer) {
+	mr.nodeMetrics.Register(registerer)
+	mr.serviceMetrics.Register(registerer)
+	mr.systemMetrics.Register(registerer)
+	mr.metricsV2.Register(registerer)
+	mr.performanceMetrics.Register(registerer)
+	mr.puppetDBMetrics.Register(registerer)
+	mr.lifecycleMetrics.Register(registerer)
+	mr.inventoryMetrics.Register(registerer)
+	mr.caMetrics.Register(registerer)
+	mr.puppetServerMetrics.Register(registerer)
+	mr.commandMetrics.Register(registerer)
+	mr.mbeanRuleMetrics.Register(registerer)
+	mr.adminMetrics.Register(registerer)
+	mr.storageMetrics.Register(registerer)
+	mr.dloMetrics.Register(registerer)
+	mr.jettyMetrics.Register(registerer)
+	mr.jvmThreadMetrics.Register(registerer)
+	mr.summaryStatsMetrics.Register(registerer)
+	mr.metaMetrics.Register(registerer)
+	mr.apiMetrics.Register(registerer)
 }
 
 // GetNodeMetrics 获取节点指标
@@ -64,6 +106,76 @@ func (mr *MetricsRegistry) GetPuppetDBMetrics() *PuppetDBMetrics {
 	return mr.puppetDBMetrics
 }
 
+// GetLifecycleMetrics 获取节点生命周期指标
+func (mr *MetricsRegistry) GetLifecycleMetrics() *LifecycleMetrics {
+	return mr.lifecycleMetrics
+}
+
+// GetInventoryMetrics 获取节点清单指标
+func (mr *MetricsRegistry) GetInventoryMetrics() *InventoryMetrics {
+	return mr.inventoryMetrics
+}
+
+// GetCAMetrics 获取 Puppet CA 指标
+func (mr *MetricsRegistry) GetCAMetrics() *CAMetrics {
+	return mr.caMetrics
+}
+
+// GetPuppetServerMetrics 获取 Puppet Server 指标
+func (mr *MetricsRegistry) GetPuppetServerMetrics() *PuppetServerMetrics {
+	return mr.puppetServerMetrics
+}
+
+// GetCommandMetrics 获取按命令划分的消息队列指标
+func (mr *MetricsRegistry) GetCommandMetrics() *CommandMetrics {
+	return mr.commandMetrics
+}
+
+// GetMBeanRuleMetrics 获取 MBean 规则生成的指标
+func (mr *MetricsRegistry) GetMBeanRuleMetrics() *MBeanRuleMetrics {
+	return mr.mbeanRuleMetrics
+}
+
+// GetAdminMetrics 获取定期清理操作指标
+func (mr *MetricsRegistry) GetAdminMetrics() *AdminMetrics {
+	return mr.adminMetrics
+}
+
+// GetStorageMetrics 获取存储层指标
+func (mr *MetricsRegistry) GetStorageMetrics() *StorageMetrics {
+	return mr.storageMetrics
+}
+
+// GetDLOMetrics 获取死信队列指标
+func (mr *MetricsRegistry) GetDLOMetrics() *DLOMetrics {
+	return mr.dloMetrics
+}
+
+// GetJettyMetrics 获取 Jetty Web 服务器指标
+func (mr *MetricsRegistry) GetJettyMetrics() *JettyMetrics {
+	return mr.jettyMetrics
+}
+
+// GetJVMThreadMetrics 获取 JVM 线程状态指标
+func (mr *MetricsRegistry) GetJVMThreadMetrics() *JVMThreadMetrics {
+	return mr.jvmThreadMetrics
+}
+
+// GetSummaryStatsMetrics 获取汇总统计指标
+func (mr *MetricsRegistry) GetSummaryStatsMetrics() *SummaryStatsMetrics {
+	return mr.summaryStatsMetrics
+}
+
+// GetMetaMetrics 获取 PuppetDB 元数据指标
+func (mr *MetricsRegistry) GetMetaMetrics() *MetaMetrics {
+	return mr.metaMetrics
+}
+
+// GetAPIMetrics 获取 API 健康指标
+func (mr *MetricsRegistry) GetAPIMetrics() *APIMetrics {
+	return mr.apiMetrics
+}
+
 // Describe 输出所有指标描述
 func (mr *MetricsRegistry) Describe(ch chan<- *prometheus.Desc) {
 	// 这里可以遍历所有指标并调用它们的 Describe 方法
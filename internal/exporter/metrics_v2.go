@@ -44,11 +44,11 @@ func NewMetricsV2(namespace string) *MetricsV2 {
 }
 
 // Register 注册所有 metrics v2 指标
-func (m2 *MetricsV2) Register() {
-	prometheus.MustRegister(m2.status)
-	prometheus.MustRegister(m2.timestamp)
-	prometheus.MustRegister(m2.info)
-	prometheus.MustRegister(m2.config)
+func (m2 *MetricsV2) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(m2.status)
+	registerer.MustRegister(m2.timestamp)
+	registerer.MustRegister(m2.info)
+	registerer.MustRegister(m2.config)
 }
 
 // UpdateMetricsV2 更新 metrics v2 相关指标
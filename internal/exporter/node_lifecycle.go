@@ -0,0 +1,51 @@
+package exporter
+
+// 节点生命周期事件
+const (
+	nodeEventAdded       = "added"
+	nodeEventDeactivated = "deactivated"
+	nodeEventReactivated = "reactivated"
+	nodeEventPurged      = "purged"
+)
+
+// NodeLifecycle 记录上一次抓取时各节点是否活跃，用于统计两次抓取之间的节点变动
+type NodeLifecycle struct {
+	initialized bool
+	active      map[string]bool
+}
+
+// NewNodeLifecycle 创建节点生命周期跟踪器
+func NewNodeLifecycle() *NodeLifecycle {
+	return &NodeLifecycle{
+		active: make(map[string]bool),
+	}
+}
+
+// Observe 记录本次抓取的节点活跃状态，返回与上一次抓取相比各类事件的数量
+// 首次调用只建立基线，不产生事件
+func (nl *NodeLifecycle) Observe(active map[string]bool) map[string]int {
+	events := make(map[string]int)
+
+	if nl.initialized {
+		for certname, isActive := range active {
+			wasActive, known := nl.active[certname]
+			switch {
+			case !known:
+				events[nodeEventAdded]++
+			case wasActive && !isActive:
+				events[nodeEventDeactivated]++
+			case !wasActive && isActive:
+				events[nodeEventReactivated]++
+			}
+		}
+		for certname := range nl.active {
+			if _, ok := active[certname]; !ok {
+				events[nodeEventPurged]++
+			}
+		}
+	}
+
+	nl.active = active
+	nl.initialized = true
+	return events
+}
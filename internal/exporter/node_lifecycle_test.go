@@ -0,0 +1,61 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeLifecycleObserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous map[string]bool
+		current  map[string]bool
+		expected map[string]int
+	}{
+		{
+			name:     "没有变化",
+			previous: map[string]bool{"web1": true, "web2": false},
+			current:  map[string]bool{"web1": true, "web2": false},
+			expected: map[string]int{},
+		},
+		{
+			name:     "新增节点",
+			previous: map[string]bool{"web1": true},
+			current:  map[string]bool{"web1": true, "web2": true, "web3": false},
+			expected: map[string]int{nodeEventAdded: 2},
+		},
+		{
+			name:     "停用和重新激活",
+			previous: map[string]bool{"web1": true, "web2": false},
+			current:  map[string]bool{"web1": false, "web2": true},
+			expected: map[string]int{nodeEventDeactivated: 1, nodeEventReactivated: 1},
+		},
+		{
+			name:     "清除节点",
+			previous: map[string]bool{"web1": true, "web2": false},
+			current:  map[string]bool{"web1": true},
+			expected: map[string]int{nodeEventPurged: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := NewNodeLifecycle()
+			// 首次调用只建立基线
+			assert.Equal(t, map[string]int{}, nl.Observe(tt.previous))
+			assert.Equal(t, tt.expected, nl.Observe(tt.current))
+		})
+	}
+}
+
+func TestNodeLifecycleObserveSequence(t *testing.T) {
+	nl := NewNodeLifecycle()
+	nl.Observe(map[string]bool{"web1": true})
+
+	// 每次只与上一次抓取比较，事件不会重复统计
+	assert.Equal(t, map[string]int{nodeEventDeactivated: 1}, nl.Observe(map[string]bool{"web1": false}))
+	assert.Equal(t, map[string]int{}, nl.Observe(map[string]bool{"web1": false}))
+	assert.Equal(t, map[string]int{nodeEventPurged: 1}, nl.Observe(map[string]bool{}))
+	assert.Equal(t, map[string]int{nodeEventAdded: 1}, nl.Observe(map[string]bool{"web1": true}))
+}
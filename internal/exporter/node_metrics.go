@@ -21,6 +21,18 @@ type NodeMetrics struct {
 	catalogAge        *prometheus.GaugeVec
 	factsAge          *prometheus.GaugeVec
 	reportMetrics     map[string]*prometheus.GaugeVec
+
+	// 报告历史指标
+	consecutiveFailures *prometheus.GaugeVec
+	lastSuccessAge      *prometheus.GaugeVec
+	flappingScore       *prometheus.GaugeVec
+
+	// 运行间隔指标
+	expectedRunInterval *prometheus.GaugeVec
+	missedRuns          *prometheus.GaugeVec
+
+	// 维护窗口指标
+	maintenance *prometheus.GaugeVec
 }
 
 // NewNodeMetrics 创建节点指标实例
@@ -80,6 +92,43 @@ func NewNodeMetrics(namespace string, categories map[string]struct{}) *NodeMetri
 		Help:      "Node facts timestamp (UNIX epoch).",
 	}, []string{"environment", "host"})
 
+	nm.consecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_consecutive_failures",
+		Help:      "Number of consecutive failed runs in the node's recent report history.",
+	}, []string{"environment", "host"})
+
+	nm.lastSuccessAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_last_success_age_seconds",
+		Help:      "Time since the node's last successful run in seconds (absent if no success within the history window).",
+	}, []string{"environment", "host"})
+
+	nm.flappingScore = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_flapping_score",
+		Help:      "Number of transitions between failed and successful runs within the history window.",
+	}, []string{"environment", "host"})
+
+	nm.expectedRunInterval = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_expected_run_interval_seconds",
+		Help:      "Expected Puppet run interval of the node in seconds (source label is fact/environment/inferred).",
+	}, []string{"environment", "host", "source"})
+
+	nm.missedRuns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_missed_runs",
+		Help:      "Number of expected Puppet runs missed since the node's latest report.",
+	}, []string{"environment", "host"})
+
+	// 维护窗口指标
+	nm.maintenance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_in_maintenance",
+		Help:      "Whether the node is in a maintenance window and excluded from health accounting (1=yes).",
+	}, []string{"environment", "host"})
+
 	nm.report = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "puppet",
 		Name:      "report",
@@ -100,19 +149,25 @@ func NewNodeMetrics(namespace string, categories map[string]struct{}) *NodeMetri
 }
 
 // Register 注册所有节点指标
-func (nm *NodeMetrics) Register() {
-	prometheus.MustRegister(nm.reportStatusCount)
-	prometheus.MustRegister(nm.hasReport)
-	prometheus.MustRegister(nm.latestReportNoop)
-	prometheus.MustRegister(nm.catalogTimestamp)
-	prometheus.MustRegister(nm.factsTimestamp)
-	prometheus.MustRegister(nm.report)
-	prometheus.MustRegister(nm.reportAge)
-	prometheus.MustRegister(nm.catalogAge)
-	prometheus.MustRegister(nm.factsAge)
+func (nm *NodeMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(nm.reportStatusCount)
+	registerer.MustRegister(nm.hasReport)
+	registerer.MustRegister(nm.latestReportNoop)
+	registerer.MustRegister(nm.catalogTimestamp)
+	registerer.MustRegister(nm.factsTimestamp)
+	registerer.MustRegister(nm.report)
+	registerer.MustRegister(nm.reportAge)
+	registerer.MustRegister(nm.catalogAge)
+	registerer.MustRegister(nm.factsAge)
+	registerer.MustRegister(nm.consecutiveFailures)
+	registerer.MustRegister(nm.lastSuccessAge)
+	registerer.MustRegister(nm.flappingScore)
+	registerer.MustRegister(nm.expectedRunInterval)
+	registerer.MustRegister(nm.missedRuns)
+	registerer.MustRegister(nm.maintenance)
 
 	for _, metric := range nm.reportMetrics {
-		prometheus.MustRegister(metric)
+		registerer.MustRegister(metric)
 	}
 }
 
@@ -120,6 +175,12 @@ func (nm *NodeMetrics) Register() {
 func (nm *NodeMetrics) Reset() {
 	nm.report.Reset()
 	nm.reportStatusCount.Reset()
+	nm.consecutiveFailures.Reset()
+	nm.lastSuccessAge.Reset()
+	nm.flappingScore.Reset()
+	nm.expectedRunInterval.Reset()
+	nm.missedRuns.Reset()
+	nm.maintenance.Reset()
 
 	for _, metric := range nm.reportMetrics {
 		metric.Reset()
@@ -197,6 +258,35 @@ func (nm *NodeMetrics) UpdateReportMetrics(node NodeInfo, reportMetrics []Report
 	}
 }
 
+// UpdateHistoryMetrics 更新报告历史指标
+func (nm *NodeMetrics) UpdateHistoryMetrics(node NodeInfo, stats NodeHistoryStats, now time.Time) {
+	if stats.Reports == 0 {
+		return
+	}
+
+	labels := prometheus.Labels{"environment": node.ReportEnvironment, "host": node.Certname}
+	nm.consecutiveFailures.With(labels).Set(float64(stats.ConsecutiveFailures))
+	nm.flappingScore.With(labels).Set(float64(stats.Transitions))
+	if !stats.LastSuccess.IsZero() {
+		nm.lastSuccessAge.With(labels).Set(now.Sub(stats.LastSuccess).Seconds())
+	}
+}
+
+// UpdateRunIntervalMetrics 更新运行间隔指标
+func (nm *NodeMetrics) UpdateRunIntervalMetrics(node NodeInfo, interval time.Duration, source string, missedRuns int) {
+	if interval <= 0 {
+		return
+	}
+
+	nm.expectedRunInterval.With(prometheus.Labels{"environment": node.ReportEnvironment, "host": node.Certname, "source": source}).Set(interval.Seconds())
+	nm.missedRuns.With(prometheus.Labels{"environment": node.ReportEnvironment, "host": node.Certname}).Set(float64(missedRuns))
+}
+
+// UpdateMaintenance 标记处于维护窗口的节点
+func (nm *NodeMetrics) UpdateMaintenance(certname string, environment string) {
+	nm.maintenance.With(prometheus.Labels{"environment": environment, "host": certname}).Set(1)
+}
+
 // UpdateStatusCount 更新状态计数
 func (nm *NodeMetrics) UpdateStatusCount(statuses map[string]int) {
 	for statusName, statusValue := range statuses {
@@ -52,11 +52,11 @@ func NewPerformanceMetrics(namespace string) *PerformanceMetrics {
 }
 
 // Register 注册所有性能指标
-func (pm *PerformanceMetrics) Register() {
-	prometheus.MustRegister(pm.scrapeDuration)
-	prometheus.MustRegister(pm.scrapeErrors)
-	prometheus.MustRegister(pm.requestDuration)
-	prometheus.MustRegister(pm.requestsTotal)
+func (pm *PerformanceMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(pm.scrapeDuration)
+	registerer.MustRegister(pm.scrapeErrors)
+	registerer.MustRegister(pm.requestDuration)
+	registerer.MustRegister(pm.requestsTotal)
 }
 
 // RecordScrapeDuration 记录抓取耗时
@@ -6,17 +6,6 @@ import (
 
 // PuppetDBMetrics 定义PuppetDB核心性能指标
 type PuppetDBMetrics struct {
-	// 命令处理指标
-	commandsProcessed          *prometheus.CounterVec
-	commandsProcessingDuration *prometheus.HistogramVec
-	commandQueueDepth          prometheus.Gauge
-
-	// 存储层指标
-	storageDuplicatePct           prometheus.Gauge
-	storageGCDuration             prometheus.Gauge
-	storageReplaceFactsDuration   prometheus.Gauge
-	storageReplaceCatalogDuration prometheus.Gauge
-
 	// 人口统计指标
 	populationNodes               prometheus.Gauge
 	populationResources           prometheus.Gauge
@@ -28,9 +17,8 @@ type PuppetDBMetrics struct {
 	httpActiveConnections prometheus.Gauge
 
 	// HTTP 端点详细指标
-	httpRequestRate            *prometheus.GaugeVec
-	httpServiceTimePercentiles *prometheus.GaugeVec
-	httpServiceTimeStats       *prometheus.GaugeVec
+	httpServiceTime *DropwizardMetric
+	httpResponses   *DropwizardMetric
 
 	// 数据库连接池指标
 	dbConnectionsActive  *prometheus.GaugeVec
@@ -39,38 +27,15 @@ type PuppetDBMetrics struct {
 	dbConnectionsPending *prometheus.GaugeVec
 	dbConnectionWaitTime *prometheus.HistogramVec
 
-	// 数据库连接池使用统计指标
-	dbPoolUsageMean           *prometheus.GaugeVec
-	dbPoolUsage75thPercentile *prometheus.GaugeVec
-	dbPoolUsage95thPercentile *prometheus.GaugeVec
-	dbPoolUsage99thPercentile *prometheus.GaugeVec
-	dbPoolUsageMax            *prometheus.GaugeVec
-
-	// 数据库连接池等待时间统计指标
-	dbPoolWaitMean           *prometheus.GaugeVec
-	dbPoolWait75thPercentile *prometheus.GaugeVec
-	dbPoolWait95thPercentile *prometheus.GaugeVec
-	dbPoolWait99thPercentile *prometheus.GaugeVec
-	dbPoolWaitMax            *prometheus.GaugeVec
-
 	// 数据库连接池配置指标
 	dbPoolMaxConnections *prometheus.GaugeVec
 	dbPoolMinConnections *prometheus.GaugeVec
 
-	// 数据库连接池连接创建统计指标
-	dbPoolConnectionCreationMean           *prometheus.GaugeVec
-	dbPoolConnectionCreation75thPercentile *prometheus.GaugeVec
-	dbPoolConnectionCreation95thPercentile *prometheus.GaugeVec
-	dbPoolConnectionCreation99thPercentile *prometheus.GaugeVec
-	dbPoolConnectionCreationMax            *prometheus.GaugeVec
-	dbPoolConnectionCreationCount          *prometheus.GaugeVec
-
-	// 数据库连接池连接超时率指标
-	dbPoolConnectionTimeoutRateOneMinute     *prometheus.GaugeVec
-	dbPoolConnectionTimeoutRateFiveMinute    *prometheus.GaugeVec
-	dbPoolConnectionTimeoutRateFifteenMinute *prometheus.GaugeVec
-	dbPoolConnectionTimeoutRateMean          *prometheus.GaugeVec
-	dbPoolConnectionTimeoutRateCount         *prometheus.GaugeVec
+	// 数据库连接池 Dropwizard 统计指标
+	dbPoolUsage              *DropwizardMetric
+	dbPoolWait               *DropwizardMetric
+	dbPoolConnectionCreation *DropwizardMetric
+	dbPoolConnectionTimeouts *DropwizardMetric
 
 	// JVM 指标
 	jvmMemoryUsed    *prometheus.GaugeVec
@@ -89,6 +54,11 @@ type PuppetDBMetrics struct {
 	jvmGCCollectionTime     *prometheus.CounterVec
 	jvmGCLastGcInfoDuration *prometheus.GaugeVec
 
+	// JVM NIO 缓冲池指标
+	jvmBufferPoolCount    *prometheus.GaugeVec
+	jvmBufferPoolUsed     *prometheus.GaugeVec
+	jvmBufferPoolCapacity *prometheus.GaugeVec
+
 	// JVM 运行时系统指标
 	jvmClassLoadingLoadedClassCount      prometheus.Gauge
 	jvmClassLoadingUnloadedClassCount    prometheus.Counter
@@ -126,66 +96,6 @@ type PuppetDBMetrics struct {
 func NewPuppetDBMetrics(namespace string) *PuppetDBMetrics {
 	pm := &PuppetDBMetrics{}
 
-	// 命令处理指标
-	pm.commandsProcessed = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: namespace,
-			Name:      "commands_processed_total",
-			Help:      "Total number of commands processed by PuppetDB",
-		},
-		[]string{"command", "version", "status"},
-	)
-
-	pm.commandsProcessingDuration = prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: namespace,
-			Name:      "commands_processing_duration_seconds",
-			Help:      "Command processing duration in seconds",
-		},
-		[]string{"command", "version"},
-	)
-
-	pm.commandQueueDepth = prometheus.NewGauge(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "command_queue_depth",
-			Help:      "Current depth of the command queue",
-		},
-	)
-
-	// 存储层指标
-	pm.storageDuplicatePct = prometheus.NewGauge(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "storage_duplicate_percentage",
-			Help:      "Percentage of catalogs that are duplicates",
-		},
-	)
-
-	pm.storageGCDuration = prometheus.NewGauge(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "storage_gc_duration_seconds",
-			Help:      "Storage garbage collection duration in seconds",
-		},
-	)
-
-	pm.storageReplaceFactsDuration = prometheus.NewGauge(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "storage_replace_facts_duration_seconds",
-			Help:      "Time taken to replace facts in seconds",
-		},
-	)
-
-	pm.storageReplaceCatalogDuration = prometheus.NewGauge(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "storage_replace_catalog_duration_seconds",
-			Help:      "Time taken to replace catalogs in seconds",
-		},
-	)
-
 	// 人口统计指标
 	pm.populationNodes = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -239,32 +149,18 @@ func NewPuppetDBMetrics(namespace string) *PuppetDBMetrics {
 	)
 
 	// HTTP 端点详细指标
-	pm.httpRequestRate = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "http_request_rate",
-			Help:      "HTTP request rate in requests per second",
-		},
-		[]string{"endpoint", "rate_type"},
-	)
-
-	pm.httpServiceTimePercentiles = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "http_service_time_percentile_seconds",
-			Help:      "HTTP service time percentiles in seconds",
-		},
-		[]string{"endpoint", "percentile"},
-	)
-
-	pm.httpServiceTimeStats = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "http_service_time_stats_seconds",
-			Help:      "HTTP service time statistics in seconds",
-		},
-		[]string{"endpoint", "stat"},
-	)
+	pm.httpServiceTime = NewDropwizardMetric(DropwizardOpts{
+		Namespace: namespace,
+		Name:      "http_service_time",
+		Unit:      "seconds",
+		Help:      "HTTP service time in seconds, by endpoint.",
+	}, []string{"endpoint"})
+
+	pm.httpResponses = NewDropwizardMetric(DropwizardOpts{
+		Namespace: namespace,
+		Name:      "http_responses",
+		Help:      "HTTP responses, by endpoint and status code.",
+	}, []string{"endpoint", "code"})
 
 	// 数据库连接池指标
 	pm.dbConnectionsActive = prometheus.NewGaugeVec(
@@ -312,98 +208,6 @@ func NewPuppetDBMetrics(namespace string) *PuppetDBMetrics {
 		[]string{"pool"},
 	)
 
-	// 数据库连接池使用统计指标
-	pm.dbPoolUsageMean = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_usage_mean",
-			Help:      "Mean value of database pool usage",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolUsage75thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_usage_75th_percentile",
-			Help:      "75th percentile of database pool usage",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolUsage95thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_usage_95th_percentile",
-			Help:      "95th percentile of database pool usage",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolUsage99thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_usage_99th_percentile",
-			Help:      "99th percentile of database pool usage",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolUsageMax = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_usage_max",
-			Help:      "Maximum value of database pool usage",
-		},
-		[]string{"pool"},
-	)
-
-	// 数据库连接池等待时间统计指标
-	pm.dbPoolWaitMean = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_wait_mean_seconds",
-			Help:      "Mean wait time for database pool connections in seconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolWait75thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_wait_75th_percentile_seconds",
-			Help:      "75th percentile of wait time for database pool connections in seconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolWait95thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_wait_95th_percentile_seconds",
-			Help:      "95th percentile of wait time for database pool connections in seconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolWait99thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_wait_99th_percentile_seconds",
-			Help:      "99th percentile of wait time for database pool connections in seconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolWaitMax = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_wait_max_seconds",
-			Help:      "Maximum wait time for database pool connections in seconds",
-		},
-		[]string{"pool"},
-	)
-
 	// 数据库连接池配置指标
 	pm.dbPoolMaxConnections = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -423,106 +227,36 @@ func NewPuppetDBMetrics(namespace string) *PuppetDBMetrics {
 		[]string{"pool"},
 	)
 
-	// 数据库连接池连接创建统计指标
-	pm.dbPoolConnectionCreationMean = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_creation_mean_ms",
-			Help:      "Mean time for database connection creation in milliseconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionCreation75thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_creation_75th_percentile_ms",
-			Help:      "75th percentile of database connection creation time in milliseconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionCreation95thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_creation_95th_percentile_ms",
-			Help:      "95th percentile of database connection creation time in milliseconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionCreation99thPercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_creation_99th_percentile_ms",
-			Help:      "99th percentile of database connection creation time in milliseconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionCreationMax = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_creation_max_ms",
-			Help:      "Maximum time for database connection creation in milliseconds",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionCreationCount = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_creation_count",
-			Help:      "Total count of database connection creation operations",
-		},
-		[]string{"pool"},
-	)
+	// 数据库连接池 Dropwizard 统计指标
+	// HikariCP 的连接使用时间和连接创建时间是以毫秒记录的直方图
+	pm.dbPoolUsage = NewDropwizardMetric(DropwizardOpts{
+		Namespace: namespace,
+		Name:      "db_pool_usage",
+		Unit:      "seconds",
+		Scale:     1e-3,
+		Help:      "Time database connections are held before being returned to the pool in seconds, by pool.",
+	}, []string{"pool"})
 
-	// 数据库连接池连接超时率指标
-	pm.dbPoolConnectionTimeoutRateOneMinute = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_timeout_rate_one_minute",
-			Help:      "One minute rate of database connection timeouts per second",
-		},
-		[]string{"pool"},
-	)
+	pm.dbPoolWait = NewDropwizardMetric(DropwizardOpts{
+		Namespace: namespace,
+		Name:      "db_pool_wait",
+		Unit:      "seconds",
+		Help:      "Time spent waiting for a database pool connection in seconds, by pool.",
+	}, []string{"pool"})
 
-	pm.dbPoolConnectionTimeoutRateFiveMinute = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_timeout_rate_five_minute",
-			Help:      "Five minute rate of database connection timeouts per second",
-		},
-		[]string{"pool"},
-	)
+	pm.dbPoolConnectionCreation = NewDropwizardMetric(DropwizardOpts{
+		Namespace: namespace,
+		Name:      "db_pool_connection_creation",
+		Unit:      "seconds",
+		Scale:     1e-3,
+		Help:      "Time spent creating database connections in seconds, by pool.",
+	}, []string{"pool"})
 
-	pm.dbPoolConnectionTimeoutRateFifteenMinute = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_timeout_rate_fifteen_minute",
-			Help:      "Fifteen minute rate of database connection timeouts per second",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionTimeoutRateMean = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_timeout_rate_mean",
-			Help:      "Mean rate of database connection timeouts per second",
-		},
-		[]string{"pool"},
-	)
-
-	pm.dbPoolConnectionTimeoutRateCount = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "db_pool_connection_timeout_rate_count",
-			Help:      "Total count of database connection timeouts",
-		},
-		[]string{"pool"},
-	)
+	pm.dbPoolConnectionTimeouts = NewDropwizardMetric(DropwizardOpts{
+		Namespace: namespace,
+		Name:      "db_pool_connection_timeouts",
+		Help:      "Database connection timeouts, by pool.",
+	}, []string{"pool"})
 
 	// JVM 指标
 	pm.jvmMemoryUsed = prometheus.NewGaugeVec(
@@ -625,6 +359,34 @@ func NewPuppetDBMetrics(namespace string) *PuppetDBMetrics {
 		[]string{"gc"},
 	)
 
+	// JVM NIO 缓冲池指标
+	pm.jvmBufferPoolCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jvm_buffer_pool_count",
+			Help:      "Number of buffers in the JVM buffer pool",
+		},
+		[]string{"pool"},
+	)
+
+	pm.jvmBufferPoolUsed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jvm_buffer_pool_used_bytes",
+			Help:      "Memory used by the JVM buffer pool in bytes",
+		},
+		[]string{"pool"},
+	)
+
+	pm.jvmBufferPoolCapacity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jvm_buffer_pool_capacity_bytes",
+			Help:      "Total capacity of the buffers in the JVM buffer pool in bytes",
+		},
+		[]string{"pool"},
+	)
+
 	// JVM 运行时系统指标
 	pm.jvmClassLoadingLoadedClassCount = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -838,143 +600,89 @@ func NewPuppetDBMetrics(namespace string) *PuppetDBMetrics {
 }
 
 // Register 注册所有PuppetDB指标
-func (pm *PuppetDBMetrics) Register() {
-	// 命令处理指标
-	prometheus.MustRegister(pm.commandsProcessed)
-	prometheus.MustRegister(pm.commandsProcessingDuration)
-	prometheus.MustRegister(pm.commandQueueDepth)
-
-	// 存储层指标
-	prometheus.MustRegister(pm.storageDuplicatePct)
-	prometheus.MustRegister(pm.storageGCDuration)
-	prometheus.MustRegister(pm.storageReplaceFactsDuration)
-	prometheus.MustRegister(pm.storageReplaceCatalogDuration)
-
+func (pm *PuppetDBMetrics) Register(registerer prometheus.Registerer) {
 	// 人口统计指标
-	prometheus.MustRegister(pm.populationNodes)
-	prometheus.MustRegister(pm.populationResources)
-	prometheus.MustRegister(pm.populationAvgResourcesPerNode)
+	registerer.MustRegister(pm.populationNodes)
+	registerer.MustRegister(pm.populationResources)
+	registerer.MustRegister(pm.populationAvgResourcesPerNode)
 
 	// HTTP 服务指标
-	prometheus.MustRegister(pm.httpRequestsTotal)
-	prometheus.MustRegister(pm.httpRequestDuration)
-	prometheus.MustRegister(pm.httpActiveConnections)
-	prometheus.MustRegister(pm.httpRequestRate)
-	prometheus.MustRegister(pm.httpServiceTimePercentiles)
-	prometheus.MustRegister(pm.httpServiceTimeStats)
+	registerer.MustRegister(pm.httpRequestsTotal)
+	registerer.MustRegister(pm.httpRequestDuration)
+	registerer.MustRegister(pm.httpActiveConnections)
+	pm.httpServiceTime.Register(registerer)
+	pm.httpResponses.Register(registerer)
 
 	// 数据库连接池指标
-	prometheus.MustRegister(pm.dbConnectionsActive)
-	prometheus.MustRegister(pm.dbConnectionsIdle)
-	prometheus.MustRegister(pm.dbConnectionsTotal)
-	prometheus.MustRegister(pm.dbConnectionsPending)
-	prometheus.MustRegister(pm.dbConnectionWaitTime)
-
-	// 数据库连接池使用统计指标
-	prometheus.MustRegister(pm.dbPoolUsageMean)
-	prometheus.MustRegister(pm.dbPoolUsage75thPercentile)
-	prometheus.MustRegister(pm.dbPoolUsage95thPercentile)
-	prometheus.MustRegister(pm.dbPoolUsage99thPercentile)
-	prometheus.MustRegister(pm.dbPoolUsageMax)
-
-	// 数据库连接池等待时间统计指标
-	prometheus.MustRegister(pm.dbPoolWaitMean)
-	prometheus.MustRegister(pm.dbPoolWait75thPercentile)
-	prometheus.MustRegister(pm.dbPoolWait95thPercentile)
-	prometheus.MustRegister(pm.dbPoolWait99thPercentile)
-	prometheus.MustRegister(pm.dbPoolWaitMax)
+	registerer.MustRegister(pm.dbConnectionsActive)
+	registerer.MustRegister(pm.dbConnectionsIdle)
+	registerer.MustRegister(pm.dbConnectionsTotal)
+	registerer.MustRegister(pm.dbConnectionsPending)
+	registerer.MustRegister(pm.dbConnectionWaitTime)
 
 	// 数据库连接池配置指标
-	prometheus.MustRegister(pm.dbPoolMaxConnections)
-	prometheus.MustRegister(pm.dbPoolMinConnections)
-
-	// 数据库连接池连接创建统计指标
-	prometheus.MustRegister(pm.dbPoolConnectionCreationMean)
-	prometheus.MustRegister(pm.dbPoolConnectionCreation75thPercentile)
-	prometheus.MustRegister(pm.dbPoolConnectionCreation95thPercentile)
-	prometheus.MustRegister(pm.dbPoolConnectionCreation99thPercentile)
-	prometheus.MustRegister(pm.dbPoolConnectionCreationMax)
-	prometheus.MustRegister(pm.dbPoolConnectionCreationCount)
-
-	// 数据库连接池连接超时率指标
-	prometheus.MustRegister(pm.dbPoolConnectionTimeoutRateOneMinute)
-	prometheus.MustRegister(pm.dbPoolConnectionTimeoutRateFiveMinute)
-	prometheus.MustRegister(pm.dbPoolConnectionTimeoutRateFifteenMinute)
-	prometheus.MustRegister(pm.dbPoolConnectionTimeoutRateMean)
-	prometheus.MustRegister(pm.dbPoolConnectionTimeoutRateCount)
+	registerer.MustRegister(pm.dbPoolMaxConnections)
+	registerer.MustRegister(pm.dbPoolMinConnections)
+
+	// 数据库连接池 Dropwizard 统计指标
+	pm.dbPoolUsage.Register(registerer)
+	pm.dbPoolWait.Register(registerer)
+	pm.dbPoolConnectionCreation.Register(registerer)
+	pm.dbPoolConnectionTimeouts.Register(registerer)
 
 	// JVM 指标
-	prometheus.MustRegister(pm.jvmMemoryUsed)
-	prometheus.MustRegister(pm.jvmMemoryMax)
-	prometheus.MustRegister(pm.jvmThreadsActive)
-	prometheus.MustRegister(pm.jvmGCDuration)
+	registerer.MustRegister(pm.jvmMemoryUsed)
+	registerer.MustRegister(pm.jvmMemoryMax)
+	registerer.MustRegister(pm.jvmThreadsActive)
+	registerer.MustRegister(pm.jvmGCDuration)
 
 	// JVM 内存池指标
-	prometheus.MustRegister(pm.jvmMemoryPoolUsed)
-	prometheus.MustRegister(pm.jvmMemoryPoolCommitted)
-	prometheus.MustRegister(pm.jvmMemoryPoolMax)
-	prometheus.MustRegister(pm.jvmMemoryPoolPeakUsed)
+	registerer.MustRegister(pm.jvmMemoryPoolUsed)
+	registerer.MustRegister(pm.jvmMemoryPoolCommitted)
+	registerer.MustRegister(pm.jvmMemoryPoolMax)
+	registerer.MustRegister(pm.jvmMemoryPoolPeakUsed)
 
 	// JVM 垃圾收集器指标
-	prometheus.MustRegister(pm.jvmGCCollectionCount)
-	prometheus.MustRegister(pm.jvmGCCollectionTime)
-	prometheus.MustRegister(pm.jvmGCLastGcInfoDuration)
+	registerer.MustRegister(pm.jvmGCCollectionCount)
+	registerer.MustRegister(pm.jvmGCCollectionTime)
+	registerer.MustRegister(pm.jvmGCLastGcInfoDuration)
 
-	// JVM 运行时系统指标
-	prometheus.MustRegister(pm.jvmClassLoadingLoadedClassCount)
-	prometheus.MustRegister(pm.jvmClassLoadingUnloadedClassCount)
-	prometheus.MustRegister(pm.jvmClassLoadingTotalLoadedClassCount)
-
-	prometheus.MustRegister(pm.jvmCompilationTotalTime)
-
-	prometheus.MustRegister(pm.jvmOperatingSystemOpenFileDescriptors)
-	prometheus.MustRegister(pm.jvmOperatingSystemCommittedVirtualMemory)
-	prometheus.MustRegister(pm.jvmOperatingSystemFreePhysicalMemory)
-	prometheus.MustRegister(pm.jvmOperatingSystemSystemLoadAverage)
-	prometheus.MustRegister(pm.jvmOperatingSystemProcessCpuLoad)
-	prometheus.MustRegister(pm.jvmOperatingSystemFreeSwapSpace)
-	prometheus.MustRegister(pm.jvmOperatingSystemTotalPhysicalMemory)
-	prometheus.MustRegister(pm.jvmOperatingSystemTotalSwapSpace)
-	prometheus.MustRegister(pm.jvmOperatingSystemProcessCpuTime)
-	prometheus.MustRegister(pm.jvmOperatingSystemMaxFileDescriptors)
-	prometheus.MustRegister(pm.jvmOperatingSystemSystemCpuLoad)
-	prometheus.MustRegister(pm.jvmOperatingSystemAvailableProcessors)
-	prometheus.MustRegister(pm.jvmOperatingSystemCpuLoad)
-	prometheus.MustRegister(pm.jvmOperatingSystemFreeMemory)
-
-	prometheus.MustRegister(pm.jvmRuntimeUptime)
-	prometheus.MustRegister(pm.jvmRuntimeStartTime)
-
-	prometheus.MustRegister(pm.jvmThreadingTotalStartedThreads)
-	prometheus.MustRegister(pm.jvmThreadingPeakThreadCount)
-	prometheus.MustRegister(pm.jvmThreadingDaemonThreadCount)
-	prometheus.MustRegister(pm.jvmThreadingCurrentThreadAllocatedBytes)
-	prometheus.MustRegister(pm.jvmThreadingThreadAllocatedMemoryEnabled)
-	prometheus.MustRegister(pm.jvmThreadingThreadCpuTimeEnabled)
-}
+	// JVM NIO 缓冲池指标
+	registerer.MustRegister(pm.jvmBufferPoolCount)
+	registerer.MustRegister(pm.jvmBufferPoolUsed)
+	registerer.MustRegister(pm.jvmBufferPoolCapacity)
 
-// UpdateCommandMetrics 更新命令处理指标
-func (pm *PuppetDBMetrics) UpdateCommandMetrics(command string, version string, status string, duration float64) {
-	pm.commandsProcessed.WithLabelValues(command, version, status).Inc()
-	if duration > 0 {
-		pm.commandsProcessingDuration.WithLabelValues(command, version).Observe(duration)
-	}
-}
-
-// UpdateStorageMetrics 更新存储层指标
-func (pm *PuppetDBMetrics) UpdateStorageMetrics(duplicatePct float64, gcDuration float64, replaceFactsDuration float64, replaceCatalogDuration float64) {
-	if duplicatePct >= 0 {
-		pm.storageDuplicatePct.Set(duplicatePct)
-	}
-	if gcDuration >= 0 {
-		pm.storageGCDuration.Set(gcDuration)
-	}
-	if replaceFactsDuration >= 0 {
-		pm.storageReplaceFactsDuration.Set(replaceFactsDuration)
-	}
-	if replaceCatalogDuration >= 0 {
-		pm.storageReplaceCatalogDuration.Set(replaceCatalogDuration)
-	}
+	// JVM 运行时系统指标
+	registerer.MustRegister(pm.jvmClassLoadingLoadedClassCount)
+	registerer.MustRegister(pm.jvmClassLoadingUnloadedClassCount)
+	registerer.MustRegister(pm.jvmClassLoadingTotalLoadedClassCount)
+
+	registerer.MustRegister(pm.jvmCompilationTotalTime)
+
+	registerer.MustRegister(pm.jvmOperatingSystemOpenFileDescriptors)
+	registerer.MustRegister(pm.jvmOperatingSystemCommittedVirtualMemory)
+	registerer.MustRegister(pm.jvmOperatingSystemFreePhysicalMemory)
+	registerer.MustRegister(pm.jvmOperatingSystemSystemLoadAverage)
+	registerer.MustRegister(pm.jvmOperatingSystemProcessCpuLoad)
+	registerer.MustRegister(pm.jvmOperatingSystemFreeSwapSpace)
+	registerer.MustRegister(pm.jvmOperatingSystemTotalPhysicalMemory)
+	registerer.MustRegister(pm.jvmOperatingSystemTotalSwapSpace)
+	registerer.MustRegister(pm.jvmOperatingSystemProcessCpuTime)
+	registerer.MustRegister(pm.jvmOperatingSystemMaxFileDescriptors)
+	registerer.MustRegister(pm.jvmOperatingSystemSystemCpuLoad)
+	registerer.MustRegister(pm.jvmOperatingSystemAvailableProcessors)
+	registerer.MustRegister(pm.jvmOperatingSystemCpuLoad)
+	registerer.MustRegister(pm.jvmOperatingSystemFreeMemory)
+
+	registerer.MustRegister(pm.jvmRuntimeUptime)
+	registerer.MustRegister(pm.jvmRuntimeStartTime)
+
+	registerer.MustRegister(pm.jvmThreadingTotalStartedThreads)
+	registerer.MustRegister(pm.jvmThreadingPeakThreadCount)
+	registerer.MustRegister(pm.jvmThreadingDaemonThreadCount)
+	registerer.MustRegister(pm.jvmThreadingCurrentThreadAllocatedBytes)
+	registerer.MustRegister(pm.jvmThreadingThreadAllocatedMemoryEnabled)
+	registerer.MustRegister(pm.jvmThreadingThreadCpuTimeEnabled)
 }
 
 // UpdatePopulationMetrics 更新人口统计指标
@@ -1021,44 +729,6 @@ func (pm *PuppetDBMetrics) UpdateDBPoolPendingConnections(pool string, pending f
 	}
 }
 
-// UpdateDBPoolUsageStats 更新数据库连接池使用统计
-func (pm *PuppetDBMetrics) UpdateDBPoolUsageStats(pool string, mean float64, p75 float64, p95 float64, p99 float64, max float64) {
-	if mean >= 0 {
-		pm.dbPoolUsageMean.WithLabelValues(pool).Set(mean)
-	}
-	if p75 >= 0 {
-		pm.dbPoolUsage75thPercentile.WithLabelValues(pool).Set(p75)
-	}
-	if p95 >= 0 {
-		pm.dbPoolUsage95thPercentile.WithLabelValues(pool).Set(p95)
-	}
-	if p99 >= 0 {
-		pm.dbPoolUsage99thPercentile.WithLabelValues(pool).Set(p99)
-	}
-	if max >= 0 {
-		pm.dbPoolUsageMax.WithLabelValues(pool).Set(max)
-	}
-}
-
-// UpdateDBPoolWaitStats 更新数据库连接池等待时间统计
-func (pm *PuppetDBMetrics) UpdateDBPoolWaitStats(pool string, mean float64, p75 float64, p95 float64, p99 float64, max float64) {
-	if mean >= 0 {
-		pm.dbPoolWaitMean.WithLabelValues(pool).Set(mean)
-	}
-	if p75 >= 0 {
-		pm.dbPoolWait75thPercentile.WithLabelValues(pool).Set(p75)
-	}
-	if p95 >= 0 {
-		pm.dbPoolWait95thPercentile.WithLabelValues(pool).Set(p95)
-	}
-	if p99 >= 0 {
-		pm.dbPoolWait99thPercentile.WithLabelValues(pool).Set(p99)
-	}
-	if max >= 0 {
-		pm.dbPoolWaitMax.WithLabelValues(pool).Set(max)
-	}
-}
-
 // UpdateDBPoolConfig 更新数据库连接池配置指标
 func (pm *PuppetDBMetrics) UpdateDBPoolConfig(pool string, maxConnections float64, minConnections float64) {
 	if maxConnections >= 0 {
@@ -1069,50 +739,21 @@ func (pm *PuppetDBMetrics) UpdateDBPoolConfig(pool string, maxConnections float6
 	}
 }
 
-// UpdateDBPoolConnectionCreationStats 更新数据库连接池连接创建统计
-func (pm *PuppetDBMetrics) UpdateDBPoolConnectionCreationStats(pool string, mean float64, p75 float64, p95 float64, p99 float64, max float64, count float64) {
-	if mean >= 0 {
-		pm.dbPoolConnectionCreationMean.WithLabelValues(pool).Set(mean)
-	}
-	if p75 >= 0 {
-		pm.dbPoolConnectionCreation75thPercentile.WithLabelValues(pool).Set(p75)
-	}
-	if p95 >= 0 {
-		pm.dbPoolConnectionCreation95thPercentile.WithLabelValues(pool).Set(p95)
-	}
-	if p99 >= 0 {
-		pm.dbPoolConnectionCreation99thPercentile.WithLabelValues(pool).Set(p99)
-	}
-	if max >= 0 {
-		pm.dbPoolConnectionCreationMax.WithLabelValues(pool).Set(max)
-	}
-	if count >= 0 {
-		pm.dbPoolConnectionCreationCount.WithLabelValues(pool).Set(count)
-	}
-}
-
-// UpdateDBPoolConnectionTimeoutRateStats 更新数据库连接池连接超时率统计
-func (pm *PuppetDBMetrics) UpdateDBPoolConnectionTimeoutRateStats(pool string, oneMinute float64, fiveMinute float64, fifteenMinute float64, mean float64, count float64) {
-	if oneMinute >= 0 {
-		pm.dbPoolConnectionTimeoutRateOneMinute.WithLabelValues(pool).Set(oneMinute)
-	}
-	if fiveMinute >= 0 {
-		pm.dbPoolConnectionTimeoutRateFiveMinute.WithLabelValues(pool).Set(fiveMinute)
-	}
-	if fifteenMinute >= 0 {
-		pm.dbPoolConnectionTimeoutRateFifteenMinute.WithLabelValues(pool).Set(fifteenMinute)
-	}
-	if mean >= 0 {
-		pm.dbPoolConnectionTimeoutRateMean.WithLabelValues(pool).Set(mean)
-	}
-	if count >= 0 {
-		pm.dbPoolConnectionTimeoutRateCount.WithLabelValues(pool).Set(count)
-	}
-}
-
-// UpdateCommandQueueDepth 更新命令队列深度
-func (pm *PuppetDBMetrics) UpdateCommandQueueDepth(depth float64) {
-	pm.commandQueueDepth.Set(depth)
+// UpdateDBPoolStats 更新数据库连接池的 Dropwizard 统计，stats 以 MBean 名称后缀为键
+func (pm *PuppetDBMetrics) UpdateDBPoolStats(stats map[string]map[string]map[string]interface{}) {
+	var usage, wait, creation, timeouts []DropwizardSample
+	for pool, data := range stats {
+		labelValues := []string{pool}
+		usage = append(usage, DropwizardSample{LabelValues: labelValues, Data: data["Usage"]})
+		wait = append(wait, DropwizardSample{LabelValues: labelValues, Data: data["Wait"]})
+		creation = append(creation, DropwizardSample{LabelValues: labelValues, Data: data["ConnectionCreation"]})
+		timeouts = append(timeouts, DropwizardSample{LabelValues: labelValues, Data: data["ConnectionTimeoutRate"]})
+	}
+
+	pm.dbPoolUsage.Update(usage)
+	pm.dbPoolWait.Update(wait)
+	pm.dbPoolConnectionCreation.Update(creation)
+	pm.dbPoolConnectionTimeouts.Update(timeouts)
 }
 
 // UpdateJVMMetrics 更新JVM指标
@@ -1131,7 +772,7 @@ func (pm *PuppetDBMetrics) UpdateJVMMetrics(memoryType string, used float64, max
 	}
 }
 
-// UpdateJVMHeapMemoryPoolMetrics 更新JVM堆内存池指标
+// UpdateJVMHeapMemoryPoolMetrics 更新JVM内存池指标（包括堆、非堆内存池和代码缓存堆）
 func (pm *PuppetDBMetrics) UpdateJVMHeapMemoryPoolMetrics(pool string, used float64, committed float64, max float64, peakUsed float64) {
 	if used >= 0 {
 		pm.jvmMemoryPoolUsed.WithLabelValues(pool).Set(used)
@@ -1160,6 +801,32 @@ func (pm *PuppetDBMetrics) UpdateJVMGarbageCollectorMetrics(gc string, collectio
 	}
 }
 
+// ResetJVMPools 清除内存池、垃圾收集器和缓冲池指标，使 JVM 中已不存在的池不再输出
+func (pm *PuppetDBMetrics) ResetJVMPools() {
+	pm.jvmMemoryPoolUsed.Reset()
+	pm.jvmMemoryPoolCommitted.Reset()
+	pm.jvmMemoryPoolMax.Reset()
+	pm.jvmMemoryPoolPeakUsed.Reset()
+	pm.jvmGCCollectionCount.Reset()
+	pm.jvmGCCollectionTime.Reset()
+	pm.jvmBufferPoolCount.Reset()
+	pm.jvmBufferPoolUsed.Reset()
+	pm.jvmBufferPoolCapacity.Reset()
+}
+
+// UpdateJVMBufferPoolMetrics 更新JVM NIO缓冲池指标
+func (pm *PuppetDBMetrics) UpdateJVMBufferPoolMetrics(pool string, count float64, used float64, capacity float64) {
+	if count >= 0 {
+		pm.jvmBufferPoolCount.WithLabelValues(pool).Set(count)
+	}
+	if used >= 0 {
+		pm.jvmBufferPoolUsed.WithLabelValues(pool).Set(used)
+	}
+	if capacity >= 0 {
+		pm.jvmBufferPoolCapacity.WithLabelValues(pool).Set(capacity)
+	}
+}
+
 // UpdateJVMClassLoadingMetrics 更新JVM类加载指标
 func (pm *PuppetDBMetrics) UpdateJVMClassLoadingMetrics(loadedClassCount float64, unloadedClassCount float64, totalLoadedClassCount float64) {
 	if loadedClassCount >= 0 {
@@ -1258,53 +925,19 @@ func (pm *PuppetDBMetrics) UpdateJVMThreadingMetrics(totalStartedThreads float64
 	}
 }
 
-// UpdateHTTPDetailedMetrics 更新HTTP端点详细指标
-func (pm *PuppetDBMetrics) UpdateHTTPDetailedMetrics(endpoint string, metrics map[string]float64) {
-	// 更新请求速率指标
-	if oneMinuteRate, ok := metrics["requests_200_one_minute_rate"]; ok && oneMinuteRate >= 0 {
-		pm.httpRequestRate.WithLabelValues(endpoint, "one_minute").Set(oneMinuteRate)
-	}
-	if fiveMinuteRate, ok := metrics["requests_200_five_minute_rate"]; ok && fiveMinuteRate >= 0 {
-		pm.httpRequestRate.WithLabelValues(endpoint, "five_minute").Set(fiveMinuteRate)
-	}
-	if fifteenMinuteRate, ok := metrics["requests_200_fifteen_minute_rate"]; ok && fifteenMinuteRate >= 0 {
-		pm.httpRequestRate.WithLabelValues(endpoint, "fifteen_minute").Set(fifteenMinuteRate)
-	}
-	if meanRate, ok := metrics["requests_200_mean_rate"]; ok && meanRate >= 0 {
-		pm.httpRequestRate.WithLabelValues(endpoint, "mean").Set(meanRate)
-	}
-
-	// 更新服务时间分位数指标
-	if p50, ok := metrics["service_time_50th_percentile"]; ok && p50 >= 0 {
-		pm.httpServiceTimePercentiles.WithLabelValues(endpoint, "50").Set(p50)
-	}
-	if p75, ok := metrics["service_time_75th_percentile"]; ok && p75 >= 0 {
-		pm.httpServiceTimePercentiles.WithLabelValues(endpoint, "75").Set(p75)
-	}
-	if p95, ok := metrics["service_time_95th_percentile"]; ok && p95 >= 0 {
-		pm.httpServiceTimePercentiles.WithLabelValues(endpoint, "95").Set(p95)
-	}
-	if p98, ok := metrics["service_time_98th_percentile"]; ok && p98 >= 0 {
-		pm.httpServiceTimePercentiles.WithLabelValues(endpoint, "98").Set(p98)
-	}
-	if p99, ok := metrics["service_time_99th_percentile"]; ok && p99 >= 0 {
-		pm.httpServiceTimePercentiles.WithLabelValues(endpoint, "99").Set(p99)
-	}
-	if p999, ok := metrics["service_time_999th_percentile"]; ok && p999 >= 0 {
-		pm.httpServiceTimePercentiles.WithLabelValues(endpoint, "999").Set(p999)
-	}
-
-	// 更新服务时间统计指标
-	if mean, ok := metrics["service_time_mean"]; ok && mean >= 0 {
-		pm.httpServiceTimeStats.WithLabelValues(endpoint, "mean").Set(mean)
-	}
-	if stddev, ok := metrics["service_time_stddev"]; ok && stddev >= 0 {
-		pm.httpServiceTimeStats.WithLabelValues(endpoint, "stddev").Set(stddev)
-	}
-	if min, ok := metrics["service_time_min"]; ok && min >= 0 {
-		pm.httpServiceTimeStats.WithLabelValues(endpoint, "min").Set(min)
-	}
-	if max, ok := metrics["service_time_max"]; ok && max >= 0 {
-		pm.httpServiceTimeStats.WithLabelValues(endpoint, "max").Set(max)
-	}
+// UpdateHTTPDetailedMetrics 更新HTTP端点详细指标，metrics 以 MBean 名称后缀（service-time 或状态码）为键
+func (pm *PuppetDBMetrics) UpdateHTTPDetailedMetrics(metrics map[string]map[string]map[string]interface{}) {
+	var serviceTimes, responses []DropwizardSample
+	for endpoint, data := range metrics {
+		for name, payload := range data {
+			if name == "service-time" {
+				serviceTimes = append(serviceTimes, DropwizardSample{LabelValues: []string{endpoint}, Data: payload})
+			} else {
+				responses = append(responses, DropwizardSample{LabelValues: []string{endpoint, name}, Data: payload})
+			}
+		}
+	}
+
+	pm.httpServiceTime.Update(serviceTimes)
+	pm.httpResponses.Update(responses)
 }
@@ -0,0 +1,167 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// puppetServerJRubyMetric jruby-metrics 字段到指标名的映射，scale 用于将毫秒转换为秒
+// 启动以来的累计次数以 counter 输出，Puppet Server 重启后由 Prometheus 处理计数器重置
+type puppetServerJRubyMetric struct {
+	name      string
+	help      string
+	scale     float64
+	valueType prometheus.ValueType
+}
+
+var puppetServerJRubyMetrics = map[string]puppetServerJRubyMetric{
+	"num-jrubies":               {"jruby_instances", "Number of JRuby instances in the pool.", 1, prometheus.GaugeValue},
+	"num-free-jrubies":          {"jruby_free_instances", "Number of JRuby instances currently free.", 1, prometheus.GaugeValue},
+	"average-free-jrubies":      {"jruby_average_free_instances", "Average number of free JRuby instances.", 1, prometheus.GaugeValue},
+	"average-requested-jrubies": {"jruby_average_requested_instances", "Average number of requested JRuby instances.", 1, prometheus.GaugeValue},
+	"average-borrow-time":       {"jruby_average_borrow_time_seconds", "Average time a JRuby instance is borrowed.", 0.001, prometheus.GaugeValue},
+	"average-wait-time":         {"jruby_average_wait_time_seconds", "Average time spent waiting for a free JRuby instance.", 0.001, prometheus.GaugeValue},
+	"average-lock-wait-time":    {"jruby_average_lock_wait_time_seconds", "Average time spent waiting for the JRuby pool lock.", 0.001, prometheus.GaugeValue},
+	"average-lock-held-time":    {"jruby_average_lock_held_time_seconds", "Average time the JRuby pool lock is held.", 0.001, prometheus.GaugeValue},
+	"borrow-count":              {"jruby_borrows_total", "Number of JRuby instances borrowed since startup.", 1, prometheus.CounterValue},
+	"borrow-timeout-count":      {"jruby_borrow_timeouts_total", "Number of JRuby borrows that timed out since startup.", 1, prometheus.CounterValue},
+	"borrow-retry-count":        {"jruby_borrow_retries_total", "Number of JRuby borrows that were retried since startup.", 1, prometheus.CounterValue},
+	"return-count":              {"jruby_returns_total", "Number of JRuby instances returned since startup.", 1, prometheus.CounterValue},
+	"queue-limit-hit-count":     {"jruby_queue_limit_hits_total", "Number of requests rejected because the JRuby queue limit was hit since startup.", 1, prometheus.CounterValue},
+	"num-pool-locks":            {"jruby_pool_locks_total", "Number of times the JRuby pool was locked since startup.", 1, prometheus.CounterValue},
+}
+
+// PuppetServerMetrics 定义 Puppet Server 状态相关的指标
+// 请求、编译和 JRuby 借用次数是 Puppet Server 启动以来的累计值，因此以常量指标的方式在收集时输出
+type PuppetServerMetrics struct {
+	mu       sync.Mutex
+	up       map[string]bool
+	statuses map[string]PuppetServerStatus
+
+	upDesc                  *prometheus.Desc
+	serviceUpDesc           *prometheus.Desc
+	jrubyDescs              map[string]*prometheus.Desc
+	httpRequestsDesc        *prometheus.Desc
+	httpRequestMeanDesc     *prometheus.Desc
+	catalogCountDesc        *prometheus.Desc
+	catalogMeanDesc         *prometheus.Desc
+	compileCountDesc        *prometheus.Desc
+	compileMeanDesc         *prometheus.Desc
+	fileSyncLastSyncDesc    *prometheus.Desc
+	fileSyncLastCheckInDesc *prometheus.Desc
+}
+
+// NewPuppetServerMetrics 创建 Puppet Server 指标实例
+func NewPuppetServerMetrics(namespace string) *PuppetServerMetrics {
+	desc := func(name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, append([]string{"server"}, labels...), nil)
+	}
+
+	pm := &PuppetServerMetrics{
+		up:         make(map[string]bool),
+		statuses:   make(map[string]PuppetServerStatus),
+		jrubyDescs: make(map[string]*prometheus.Desc),
+
+		upDesc:                  desc("up", "Whether the last status request to the Puppet Server succeeded (1=yes, 0=no)."),
+		serviceUpDesc:           desc("service_up", "Whether the Puppet Server service is running (1=running, 0=not running).", "service", "version", "state"),
+		httpRequestsDesc:        desc("http_requests_total", "Number of HTTP requests handled since startup, by route.", "route"),
+		httpRequestMeanDesc:     desc("http_request_mean_seconds", "Mean HTTP request duration by route.", "route"),
+		catalogCountDesc:        desc("catalog_operations_total", "Number of catalog compilation steps recorded by the profiler since startup.", "metric"),
+		catalogMeanDesc:         desc("catalog_operation_mean_seconds", "Mean duration of catalog compilation steps recorded by the profiler.", "metric"),
+		compileCountDesc:        desc("compiles_total", "Number of catalogs compiled since startup, by environment.", "environment"),
+		compileMeanDesc:         desc("compile_mean_seconds", "Mean catalog compile time by environment.", "environment"),
+		fileSyncLastSyncDesc:    desc("file_sync_last_successful_sync_timestamp_seconds", "Time of the last successful file-sync of the Puppet Server (UNIX epoch)."),
+		fileSyncLastCheckInDesc: desc("file_sync_last_check_in_timestamp_seconds", "Time of the last file-sync check-in of the Puppet Server (UNIX epoch)."),
+	}
+
+	for key, metric := range puppetServerJRubyMetrics {
+		pm.jrubyDescs[key] = desc(metric.name, metric.help)
+	}
+
+	return pm
+}
+
+// Register 注册所有 Puppet Server 指标
+func (pm *PuppetServerMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(pm)
+}
+
+// UpdateUp 更新 Puppet Server 是否可达，不可达时不再输出该服务器上一次的状态
+func (pm *PuppetServerMetrics) UpdateUp(server string, up bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.up[server] = up
+	if !up {
+		delete(pm.statuses, server)
+	}
+}
+
+// UpdateStatus 保存 Puppet Server 最新的状态
+func (pm *PuppetServerMetrics) UpdateStatus(server string, status PuppetServerStatus) {
+	pm.mu.Lock()
+	pm.statuses[server] = status
+	pm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (pm *PuppetServerMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pm.upDesc
+	ch <- pm.serviceUpDesc
+	for _, desc := range pm.jrubyDescs {
+		ch <- desc
+	}
+	ch <- pm.httpRequestsDesc
+	ch <- pm.httpRequestMeanDesc
+	ch <- pm.catalogCountDesc
+	ch <- pm.catalogMeanDesc
+	ch <- pm.compileCountDesc
+	ch <- pm.compileMeanDesc
+	ch <- pm.fileSyncLastSyncDesc
+	ch <- pm.fileSyncLastCheckInDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (pm *PuppetServerMetrics) Collect(ch chan<- prometheus.Metric) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	for server, up := range pm.up {
+		ch <- prometheus.MustNewConstMetric(pm.upDesc, prometheus.GaugeValue, boolToFloat(up), server)
+	}
+
+	for server, status := range pm.statuses {
+		for _, svc := range status.Services {
+			ch <- prometheus.MustNewConstMetric(pm.serviceUpDesc, prometheus.GaugeValue, boolToFloat(svc.Up), server, svc.Name, svc.Version, svc.State)
+		}
+
+		for key, value := range status.JRuby {
+			if metric, ok := puppetServerJRubyMetrics[key]; ok {
+				ch <- prometheus.MustNewConstMetric(pm.jrubyDescs[key], metric.valueType, value*metric.scale, server)
+			}
+		}
+
+		for _, route := range status.HTTPRoutes {
+			ch <- prometheus.MustNewConstMetric(pm.httpRequestsDesc, prometheus.CounterValue, route.Count, server, route.Name)
+			ch <- prometheus.MustNewConstMetric(pm.httpRequestMeanDesc, prometheus.GaugeValue, route.Mean/1000, server, route.Name)
+		}
+
+		for _, catalog := range status.CatalogMetrics {
+			ch <- prometheus.MustNewConstMetric(pm.catalogCountDesc, prometheus.CounterValue, catalog.Count, server, catalog.Name)
+			ch <- prometheus.MustNewConstMetric(pm.catalogMeanDesc, prometheus.GaugeValue, catalog.Mean/1000, server, catalog.Name)
+		}
+
+		for environment, compile := range status.CompileTimes {
+			ch <- prometheus.MustNewConstMetric(pm.compileCountDesc, prometheus.CounterValue, compile.Count, server, environment)
+			ch <- prometheus.MustNewConstMetric(pm.compileMeanDesc, prometheus.GaugeValue, compile.Mean/1000, server, environment)
+		}
+
+		if !status.FileSyncLastSync.IsZero() {
+			ch <- prometheus.MustNewConstMetric(pm.fileSyncLastSyncDesc, prometheus.GaugeValue, float64(status.FileSyncLastSync.Unix()), server)
+		}
+		if !status.FileSyncLastCheckIn.IsZero() {
+			ch <- prometheus.MustNewConstMetric(pm.fileSyncLastCheckInDesc, prometheus.GaugeValue, float64(status.FileSyncLastCheckIn.Unix()), server)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/camptocamp/prometheus-puppetdb-exporter/internal/puppetdb"
+)
+
+// PuppetServerStatus 从 Puppet Server 的 /status/v1/services?level=debug 解析出的状态
+type PuppetServerStatus struct {
+	Services []ServiceInfo
+	// JRuby jruby-metrics 服务中的数值指标，键为原始字段名（例如 average-borrow-time）
+	JRuby map[string]float64
+	// HTTPRoutes 按路由的 HTTP 请求统计
+	HTTPRoutes []PuppetServerTiming
+	// CatalogMetrics puppet-profiler 的编录编译阶段统计
+	CatalogMetrics []PuppetServerTiming
+	// CompileTimes 按环境的编录编译统计，键为环境名
+	CompileTimes map[string]PuppetServerTiming
+	// FileSyncLastSync file-sync 客户端最近一次成功同步的时间
+	FileSyncLastSync time.Time
+	// FileSyncLastCheckIn file-sync 客户端最近一次签到的时间
+	FileSyncLastCheckIn time.Time
+}
+
+// PuppetServerTiming Puppet Server 状态中的计时统计，时间单位为毫秒
+type PuppetServerTiming struct {
+	Name      string
+	Count     float64
+	Mean      float64
+	Aggregate float64
+}
+
+// compileMetricPrefix puppet-profiler 中按环境的编译指标前缀（compiler.compile.<environment>）
+const compileMetricPrefix = "compiler.compile."
+
+// parsePuppetServerStatus 解析 Puppet Server 的服务状态
+func parsePuppetServerStatus(services map[string]puppetdb.ServiceInfo) PuppetServerStatus {
+	status := PuppetServerStatus{
+		JRuby:        make(map[string]float64),
+		CompileTimes: make(map[string]PuppetServerTiming),
+	}
+
+	for name, info := range services {
+		status.Services = append(status.Services, convertServiceInfo(name, info))
+
+		experimental, _ := info.Status["experimental"].(map[string]interface{})
+
+		switch name {
+		case "jruby-metrics":
+			metrics, _ := experimental["metrics"].(map[string]interface{})
+			for key, value := range metrics {
+				if v, ok := value.(float64); ok {
+					status.JRuby[key] = v
+				}
+			}
+		case "master", "server":
+			status.HTTPRoutes = append(status.HTTPRoutes, parsePuppetServerTimings(experimental["http-metrics"], "route-id")...)
+		case "puppet-profiler":
+			for _, timing := range parsePuppetServerTimings(experimental["catalog-metrics"], "metric") {
+				if strings.HasPrefix(timing.Name, compileMetricPrefix) {
+					environment := strings.TrimPrefix(timing.Name, compileMetricPrefix)
+					if environment != "" && !strings.Contains(environment, ".") {
+						status.CompileTimes[environment] = timing
+					}
+					continue
+				}
+				status.CatalogMetrics = append(status.CatalogMetrics, timing)
+			}
+		case "file-sync-client-service":
+			if t, err := time.Parse(time.RFC3339, toString(info.Status["last_successful_sync_time"])); err == nil {
+				status.FileSyncLastSync = t
+			}
+			if t, err := time.Parse(time.RFC3339, toString(info.Status["last_check_in_time"])); err == nil {
+				status.FileSyncLastCheckIn = t
+			}
+		}
+	}
+
+	return status
+}
+
+// parsePuppetServerTimings 解析形如 [{"<nameKey>": ..., "count": ..., "mean": ..., "aggregate": ...}] 的计时列表
+func parsePuppetServerTimings(value interface{}, nameKey string) []PuppetServerTiming {
+	entries, _ := value.([]interface{})
+	timings := make([]PuppetServerTiming, 0, len(entries))
+	for _, entry := range entries {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := toString(m[nameKey])
+		if name == "" {
+			continue
+		}
+		timings = append(timings, PuppetServerTiming{
+			Name:      name,
+			Count:     numberToFloat(m["count"]),
+			Mean:      numberToFloat(m["mean"]),
+			Aggregate: numberToFloat(m["aggregate"]),
+		})
+	}
+	return timings
+}
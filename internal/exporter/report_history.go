@@ -0,0 +1,119 @@
+package exporter
+
+import (
+	"sort"
+	"time"
+)
+
+// ReportHistory 按节点记录回溯窗口内的报告状态，用于区分偶发失败与持续故障
+type ReportHistory struct {
+	window   time.Duration
+	lastSeen time.Time
+	reports  map[string][]ReportEntry
+}
+
+// ReportEntry 历史报告条目
+type ReportEntry struct {
+	Hash        string
+	Status      string
+	Time        time.Time
+	ReceiveTime time.Time
+}
+
+// NodeHistoryStats 单个节点的报告历史统计
+type NodeHistoryStats struct {
+	// ConsecutiveFailures 最近连续失败的运行次数
+	ConsecutiveFailures int
+	// LastSuccess 窗口内最近一次成功运行的时间，窗口内没有成功运行时为零值
+	LastSuccess time.Time
+	// Transitions 窗口内成功与失败之间的切换次数
+	Transitions int
+	// Reports 窗口内的报告数
+	Reports int
+}
+
+// NewReportHistory 创建报告历史记录
+func NewReportHistory(window time.Duration) *ReportHistory {
+	return &ReportHistory{
+		window:  window,
+		reports: make(map[string][]ReportEntry),
+	}
+}
+
+// Since 返回下一次增量查询的起始时间
+func (rh *ReportHistory) Since(now time.Time) time.Time {
+	start := now.Add(-rh.window)
+	if rh.lastSeen.After(start) {
+		return rh.lastSeen
+	}
+	return start
+}
+
+// Add 记录新收到的报告
+func (rh *ReportHistory) Add(certname string, entry ReportEntry) {
+	for _, existing := range rh.reports[certname] {
+		if existing.Hash == entry.Hash {
+			return
+		}
+	}
+	rh.reports[certname] = append(rh.reports[certname], entry)
+	if entry.ReceiveTime.After(rh.lastSeen) {
+		rh.lastSeen = entry.ReceiveTime
+	}
+}
+
+// Prune 清理超出回溯窗口的报告并按时间排序
+func (rh *ReportHistory) Prune(now time.Time) {
+	start := now.Add(-rh.window)
+	for certname, entries := range rh.reports {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.Time.After(start) {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(rh.reports, certname)
+			continue
+		}
+		sort.Slice(kept, func(i, j int) bool { return kept[i].Time.Before(kept[j].Time) })
+		rh.reports[certname] = kept
+	}
+}
+
+// Stats 计算节点的报告历史统计
+func (rh *ReportHistory) Stats(certname string) NodeHistoryStats {
+	entries := rh.reports[certname]
+	stats := NodeHistoryStats{Reports: len(entries)}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Status != "failed" {
+			stats.LastSuccess = entries[i].Time
+			break
+		}
+		stats.ConsecutiveFailures++
+	}
+
+	for i := 1; i < len(entries); i++ {
+		if (entries[i].Status == "failed") != (entries[i-1].Status == "failed") {
+			stats.Transitions++
+		}
+	}
+
+	return stats
+}
+
+// MedianInterval 返回节点相邻两次报告之间的中位间隔，报告数不足三次时返回 0
+func (rh *ReportHistory) MedianInterval(certname string) time.Duration {
+	entries := rh.reports[certname]
+	if len(entries) < 3 {
+		return 0
+	}
+
+	intervals := make([]time.Duration, 0, len(entries)-1)
+	for i := 1; i < len(entries); i++ {
+		intervals = append(intervals, entries[i].Time.Sub(entries[i-1].Time))
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// historyStart 测试使用的固定起始时间
+var historyStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestHistory 按状态和相对 historyStart 的分钟数创建单个节点的报告历史
+func newTestHistory(statuses []string, minutes []int) *ReportHistory {
+	rh := NewReportHistory(24 * time.Hour)
+	for i, status := range statuses {
+		at := historyStart.Add(time.Duration(minutes[i]) * time.Minute)
+		rh.Add("web1", ReportEntry{Hash: status + at.String(), Status: status, Time: at, ReceiveTime: at})
+	}
+	return rh
+}
+
+func TestReportHistoryStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		expected NodeHistoryStats
+	}{
+		{
+			name:     "无报告",
+			expected: NodeHistoryStats{},
+		},
+		{
+			name:     "全部成功",
+			statuses: []string{"unchanged", "changed", "unchanged"},
+			expected: NodeHistoryStats{Reports: 3, LastSuccess: historyStart.Add(60 * time.Minute)},
+		},
+		{
+			name:     "最近连续失败",
+			statuses: []string{"unchanged", "changed", "failed", "failed"},
+			expected: NodeHistoryStats{Reports: 4, ConsecutiveFailures: 2, Transitions: 1, LastSuccess: historyStart.Add(30 * time.Minute)},
+		},
+		{
+			name:     "全部失败时没有最近成功时间",
+			statuses: []string{"failed", "failed"},
+			expected: NodeHistoryStats{Reports: 2, ConsecutiveFailures: 2},
+		},
+		{
+			name:     "成功与失败交替",
+			statuses: []string{"failed", "unchanged", "failed", "changed"},
+			expected: NodeHistoryStats{Reports: 4, Transitions: 3, LastSuccess: historyStart.Add(90 * time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minutes := make([]int, len(tt.statuses))
+			for i := range minutes {
+				minutes[i] = i * 30
+			}
+			rh := newTestHistory(tt.statuses, minutes)
+			assert.Equal(t, tt.expected, rh.Stats("web1"))
+		})
+	}
+}
+
+func TestReportHistoryMedianInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		minutes  []int
+		expected time.Duration
+	}{
+		{name: "报告数不足", minutes: []int{0, 30}, expected: 0},
+		{name: "固定间隔", minutes: []int{0, 30, 60, 90}, expected: 30 * time.Minute},
+		// 间隔为 30、30、120、30，中位数不受离群的 120 影响
+		{name: "离群间隔", minutes: []int{0, 30, 60, 180, 210}, expected: 30 * time.Minute},
+		// 间隔排序后为 10、20、40，取中间值
+		{name: "不同间隔", minutes: []int{0, 40, 50, 70}, expected: 20 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := make([]string, len(tt.minutes))
+			for i := range statuses {
+				statuses[i] = "unchanged"
+			}
+			assert.Equal(t, tt.expected, newTestHistory(statuses, tt.minutes).MedianInterval("web1"))
+		})
+	}
+}
+
+func TestReportHistoryAdd(t *testing.T) {
+	rh := NewReportHistory(time.Hour)
+	first := ReportEntry{Hash: "a", Status: "failed", Time: historyStart, ReceiveTime: historyStart.Add(time.Minute)}
+
+	rh.Add("web1", first)
+	// 增量查询从最后接收时间开始，同一报告可能被再次返回
+	rh.Add("web1", ReportEntry{Hash: "a", Status: "unchanged", Time: historyStart, ReceiveTime: historyStart.Add(time.Minute)})
+	assert.Equal(t, []ReportEntry{first}, rh.reports["web1"])
+
+	// 较早接收的报告不会使下一次查询的起始时间回退
+	rh.Add("web1", ReportEntry{Hash: "b", Status: "unchanged", Time: historyStart, ReceiveTime: historyStart})
+	assert.Len(t, rh.reports["web1"], 2)
+	assert.Equal(t, historyStart.Add(time.Minute), rh.Since(historyStart.Add(30*time.Minute)))
+	assert.Equal(t, historyStart.Add(time.Hour), rh.Since(historyStart.Add(2*time.Hour)))
+}
+
+func TestReportHistoryPrune(t *testing.T) {
+	rh := NewReportHistory(time.Hour)
+	at := func(minutes int) time.Time { return historyStart.Add(time.Duration(minutes) * time.Minute) }
+
+	// 乱序添加
+	rh.Add("web1", ReportEntry{Hash: "c", Status: "unchanged", Time: at(100)})
+	rh.Add("web1", ReportEntry{Hash: "a", Status: "unchanged", Time: at(10)})
+	rh.Add("web1", ReportEntry{Hash: "b", Status: "failed", Time: at(70)})
+	rh.Add("web2", ReportEntry{Hash: "d", Status: "unchanged", Time: at(20)})
+
+	rh.Prune(at(120))
+
+	assert.Equal(t, []ReportEntry{
+		{Hash: "b", Status: "failed", Time: at(70)},
+		{Hash: "c", Status: "unchanged", Time: at(100)},
+	}, rh.reports["web1"])
+	// 窗口内没有报告的节点被删除
+	_, ok := rh.reports["web2"]
+	assert.False(t, ok)
+}
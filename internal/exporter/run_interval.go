@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"strconv"
+	"time"
+)
+
+// 运行间隔来源
+const (
+	runIntervalSourceFact        = "fact"
+	runIntervalSourceEnvironment = "environment"
+	runIntervalSourceInferred    = "inferred"
+)
+
+// RunIntervalResolver 按节点解析期望的 Puppet 运行间隔，并据此计算未报告阈值
+type RunIntervalResolver struct {
+	environments     map[string]time.Duration
+	missedRuns       int
+	defaultThreshold time.Duration
+	history          *ReportHistory
+	facts            map[string]time.Duration
+}
+
+// NewRunIntervalResolver 创建运行间隔解析器
+// history 为 nil 时不根据报告历史推断运行间隔
+func NewRunIntervalResolver(environments map[string]time.Duration, missedRuns int, defaultThreshold time.Duration, history *ReportHistory) *RunIntervalResolver {
+	if missedRuns < 1 {
+		missedRuns = 1
+	}
+	return &RunIntervalResolver{
+		environments:     environments,
+		missedRuns:       missedRuns,
+		defaultThreshold: defaultThreshold,
+		history:          history,
+		facts:            make(map[string]time.Duration),
+	}
+}
+
+// SetFacts 更新从事实中读取的运行间隔
+func (r *RunIntervalResolver) SetFacts(facts map[string]interface{}) {
+	r.facts = make(map[string]time.Duration, len(facts))
+	for certname, value := range facts {
+		if interval := parseRunInterval(value); interval > 0 {
+			r.facts[certname] = interval
+		}
+	}
+}
+
+// Resolve 返回节点期望的运行间隔及其来源，无法确定时返回 0
+// 优先级：事实 > 环境覆盖 > 报告历史推断
+func (r *RunIntervalResolver) Resolve(certname string, environment string) (time.Duration, string) {
+	if interval, ok := r.facts[certname]; ok {
+		return interval, runIntervalSourceFact
+	}
+	if interval, ok := r.environments[environment]; ok && interval > 0 {
+		return interval, runIntervalSourceEnvironment
+	}
+	if r.history != nil {
+		if interval := r.history.MedianInterval(certname); interval > 0 {
+			return interval, runIntervalSourceInferred
+		}
+	}
+	return 0, ""
+}
+
+// Threshold 返回节点的未报告阈值，无法确定运行间隔时使用全局阈值
+func (r *RunIntervalResolver) Threshold(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return r.defaultThreshold
+	}
+	return interval * time.Duration(r.missedRuns)
+}
+
+// MissedRuns 返回按期望运行间隔计算的错过运行次数
+func MissedRuns(reportAge time.Duration, interval time.Duration) int {
+	if interval <= 0 || reportAge < interval {
+		return 0
+	}
+	return int(reportAge / interval)
+}
+
+// parseRunInterval 解析运行间隔事实，支持秒数或 Go duration 格式（例如 "30m"）
+func parseRunInterval(value interface{}) time.Duration {
+	switch v := value.(type) {
+	case float64:
+		return time.Duration(v * float64(time.Second))
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRunInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected time.Duration
+	}{
+		{name: "秒数", value: float64(1800), expected: 30 * time.Minute},
+		{name: "小数秒数", value: 1.5, expected: 1500 * time.Millisecond},
+		{name: "duration 字符串", value: "30m", expected: 30 * time.Minute},
+		{name: "秒数字符串", value: "3600", expected: time.Hour},
+		{name: "无效字符串", value: "hourly", expected: 0},
+		{name: "不支持的类型", value: true, expected: 0},
+		{name: "空值", value: nil, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseRunInterval(tt.value))
+		})
+	}
+}
+
+func TestRunIntervalResolverResolve(t *testing.T) {
+	// web1 有三次间隔 10 分钟的报告，可以推断运行间隔
+	history := NewReportHistory(24 * time.Hour)
+	for i, hash := range []string{"a", "b", "c"} {
+		at := historyStart.Add(time.Duration(i) * 10 * time.Minute)
+		history.Add("web1", ReportEntry{Hash: hash, Status: "unchanged", Time: at, ReceiveTime: at})
+	}
+
+	resolver := NewRunIntervalResolver(map[string]time.Duration{"production": time.Hour, "staging": 0}, 2, 2*time.Hour, history)
+	facts := map[string]interface{}{"web1": "15m", "web2": "invalid"}
+
+	tests := []struct {
+		name             string
+		certname         string
+		environment      string
+		facts            map[string]interface{}
+		expectedInterval time.Duration
+		expectedSource   string
+	}{
+		{name: "事实优先于环境和推断", certname: "web1", environment: "production", facts: facts, expectedInterval: 15 * time.Minute, expectedSource: runIntervalSourceFact},
+		{name: "无效事实时使用环境覆盖", certname: "web2", environment: "production", facts: facts, expectedInterval: time.Hour, expectedSource: runIntervalSourceEnvironment},
+		{name: "环境覆盖优先于推断", certname: "web1", environment: "production", expectedInterval: time.Hour, expectedSource: runIntervalSourceEnvironment},
+		{name: "环境覆盖为 0 时使用推断", certname: "web1", environment: "staging", expectedInterval: 10 * time.Minute, expectedSource: runIntervalSourceInferred},
+		{name: "无法确定", certname: "web2", environment: "staging", expectedInterval: 0, expectedSource: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver.SetFacts(tt.facts)
+			interval, source := resolver.Resolve(tt.certname, tt.environment)
+			assert.Equal(t, tt.expectedInterval, interval)
+			assert.Equal(t, tt.expectedSource, source)
+		})
+	}
+
+	// 未提供报告历史时不推断
+	interval, source := NewRunIntervalResolver(nil, 2, 2*time.Hour, nil).Resolve("web1", "production")
+	assert.Equal(t, time.Duration(0), interval)
+	assert.Equal(t, "", source)
+}
+
+func TestRunIntervalResolverThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		missedRuns int
+		interval   time.Duration
+		expected   time.Duration
+	}{
+		{name: "未知间隔使用全局阈值", missedRuns: 3, interval: 0, expected: 2 * time.Hour},
+		{name: "间隔乘以错过次数", missedRuns: 3, interval: 30 * time.Minute, expected: 90 * time.Minute},
+		{name: "错过次数至少为 1", missedRuns: 0, interval: 30 * time.Minute, expected: 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewRunIntervalResolver(nil, tt.missedRuns, 2*time.Hour, nil)
+			assert.Equal(t, tt.expected, resolver.Threshold(tt.interval))
+		})
+	}
+}
+
+func TestMissedRuns(t *testing.T) {
+	tests := []struct {
+		name      string
+		reportAge time.Duration
+		interval  time.Duration
+		expected  int
+	}{
+		{name: "未知间隔", reportAge: time.Hour, interval: 0, expected: 0},
+		{name: "未超过一次间隔", reportAge: 20 * time.Minute, interval: 30 * time.Minute, expected: 0},
+		{name: "向下取整", reportAge: 100 * time.Minute, interval: 30 * time.Minute, expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MissedRuns(tt.reportAge, tt.interval))
+		})
+	}
+}
@@ -1,14 +1,22 @@
 package exporter
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // ServiceMetrics 定义服务相关的指标
 type ServiceMetrics struct {
-	up         *prometheus.GaugeVec
-	info       *prometheus.GaugeVec
-	queueDepth *prometheus.GaugeVec
+	up              *prometheus.GaugeVec
+	info            *prometheus.GaugeVec
+	queueDepth      *prometheus.GaugeVec
+	readDBUp        *prometheus.GaugeVec
+	writeDBUp       *prometheus.GaugeVec
+	maintenanceMode *prometheus.GaugeVec
+	databaseUp      *prometheus.GaugeVec
+	statusInfo      *prometheus.GaugeVec
+	activeAlerts    *prometheus.GaugeVec
 }
 
 // NewServiceMetrics 创建服务指标实例
@@ -33,14 +41,56 @@ func NewServiceMetrics(namespace string) *ServiceMetrics {
 		Help:      "Service queue depth (indicating number of unprocessed tasks).",
 	}, []string{"service"})
 
+	sm.readDBUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_read_db_up",
+		Help:      "Whether the service can reach its read database (1=yes, 0=no).",
+	}, []string{"service"})
+
+	sm.writeDBUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_write_db_up",
+		Help:      "Whether the service can reach its write databases (1=yes, 0=no).",
+	}, []string{"service"})
+
+	sm.maintenanceMode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_maintenance_mode",
+		Help:      "Whether the service is in maintenance mode (1=yes, 0=no).",
+	}, []string{"service"})
+
+	sm.databaseUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_database_up",
+		Help:      "Whether an individual read or write database of the service is up (1=yes, 0=no).",
+	}, []string{"service", "role", "database"})
+
+	sm.statusInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_status_info",
+		Help:      "Detail level and status format version of the service status payload (always 1).",
+	}, []string{"service", "detail_level", "status_version"})
+
+	sm.activeAlerts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_active_alerts",
+		Help:      "Number of active alerts reported by the service, by severity.",
+	}, []string{"service", "severity"})
+
 	return sm
 }
 
 // Register 注册所有服务指标
-func (sm *ServiceMetrics) Register() {
-	prometheus.MustRegister(sm.up)
-	prometheus.MustRegister(sm.info)
-	prometheus.MustRegister(sm.queueDepth)
+func (sm *ServiceMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(sm.up)
+	registerer.MustRegister(sm.info)
+	registerer.MustRegister(sm.queueDepth)
+	registerer.MustRegister(sm.readDBUp)
+	registerer.MustRegister(sm.writeDBUp)
+	registerer.MustRegister(sm.maintenanceMode)
+	registerer.MustRegister(sm.databaseUp)
+	registerer.MustRegister(sm.statusInfo)
+	registerer.MustRegister(sm.activeAlerts)
 }
 
 // Reset 重置所有服务指标
@@ -48,6 +98,12 @@ func (sm *ServiceMetrics) Reset() {
 	sm.up.Reset()
 	sm.queueDepth.Reset()
 	sm.info.Reset()
+	sm.readDBUp.Reset()
+	sm.writeDBUp.Reset()
+	sm.maintenanceMode.Reset()
+	sm.databaseUp.Reset()
+	sm.statusInfo.Reset()
+	sm.activeAlerts.Reset()
 }
 
 // UpdateServiceMetrics 更新服务指标
@@ -57,6 +113,7 @@ func (sm *ServiceMetrics) UpdateServiceMetrics(services []ServiceInfo) {
 		if svcName == "" {
 			svcName = "puppetdb"
 		}
+		labels := prometheus.Labels{"service": svcName}
 
 		// 服务状态
 		if svc.Up {
@@ -67,18 +124,53 @@ func (sm *ServiceMetrics) UpdateServiceMetrics(services []ServiceInfo) {
 
 		// 服务信息
 		sm.info.With(prometheus.Labels{"service": svcName, "version": svc.Version, "state": svc.State}).Set(1)
+		sm.statusInfo.With(prometheus.Labels{"service": svcName, "detail_level": svc.DetailLevel, "status_version": strconv.Itoa(svc.StatusVersion)}).Set(1)
+
+		// 队列深度，只有 puppetdb-status 提供
+		if svc.HasQueueDepth {
+			sm.queueDepth.With(labels).Set(float64(svc.QueueDepth))
+		}
+
+		// 数据库和维护模式状态
+		if v, ok := svc.Flags["read_db_up"]; ok {
+			sm.readDBUp.With(labels).Set(boolToFloat(v))
+		}
+		if v, ok := svc.Flags["write_db_up"]; ok {
+			sm.writeDBUp.With(labels).Set(boolToFloat(v))
+		}
+		if v, ok := svc.Flags["maintenance_mode"]; ok {
+			sm.maintenanceMode.With(labels).Set(boolToFloat(v))
+		}
+		for database, up := range svc.ReadDatabases {
+			sm.databaseUp.With(prometheus.Labels{"service": svcName, "role": "read", "database": database}).Set(boolToFloat(up))
+		}
+		for database, up := range svc.WriteDatabases {
+			sm.databaseUp.With(prometheus.Labels{"service": svcName, "role": "write", "database": database}).Set(boolToFloat(up))
+		}
 
-		// 队列深度
-		sm.queueDepth.With(prometheus.Labels{"service": svcName}).Set(float64(svc.QueueDepth))
+		// 活跃告警
+		for severity, count := range svc.Alerts {
+			sm.activeAlerts.With(prometheus.Labels{"service": svcName, "severity": severity}).Set(float64(count))
+		}
 	}
 }
 
 // ServiceInfo 服务信息结构
 // 用于从PuppetDB API获取服务状态信息
 type ServiceInfo struct {
-	Name       string
-	Version    string
-	State      string
-	Up         bool
-	QueueDepth int
+	Name          string
+	Version       string
+	State         string
+	Up            bool
+	QueueDepth    int
+	HasQueueDepth bool
+	DetailLevel   string
+	StatusVersion int
+	// Flags 状态中的布尔字段（read_db_up、write_db_up、maintenance_mode），键去掉了末尾的问号
+	Flags map[string]bool
+	// ReadDatabases/WriteDatabases 按数据库名称的连接状态
+	ReadDatabases  map[string]bool
+	WriteDatabases map[string]bool
+	// Alerts 按严重级别统计的活跃告警数
+	Alerts map[string]int
 }
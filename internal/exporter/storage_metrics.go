@@ -0,0 +1,164 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// storageMetric 存储层 MBean 对应的指标名称和说明
+type storageMetric struct {
+	name string
+	help string
+}
+
+// storageTimers 存储层计时器 MBean 到指标的映射
+var storageTimers = map[string]storageMetric{
+	"store-report-time":       {"store_report_duration", "Time taken to store a report in seconds."},
+	"new-catalog-time":        {"new_catalog_duration", "Time taken to store a catalog for a new node in seconds."},
+	"replace-catalog-time":    {"replace_catalog_duration", "Time taken to replace catalogs in seconds."},
+	"replace-facts-time":      {"replace_facts_duration", "Time taken to replace facts in seconds."},
+	"new-fact-time":           {"new_fact_duration", "Time taken to store facts for a new node in seconds."},
+	"catalog-hash":            {"catalog_hash_duration", "Time taken to compute catalog hashes in seconds."},
+	"catalog-hash-match-time": {"catalog_hash_match_duration", "Time taken to handle a catalog whose hash matches the stored one in seconds."},
+	"catalog-hash-miss-time":  {"catalog_hash_miss_duration", "Time taken to handle a catalog whose hash differs from the stored one in seconds."},
+	"add-resources":           {"add_resources_duration", "Time taken to add catalog resources in seconds."},
+	"add-edges":               {"add_edges_duration", "Time taken to add catalog edges in seconds."},
+	"resource-hashes":         {"resource_hashes_duration", "Time taken to compute resource hashes in seconds."},
+	"gc-time":                 {"gc_duration", "Storage garbage collection duration in seconds."},
+}
+
+// storageGCTables 按表划分的垃圾回收计时器 MBean 到 table 标签值的映射
+var storageGCTables = map[string]string{
+	"gc-catalogs-time":     "catalogs",
+	"gc-environments-time": "environments",
+	"gc-fact-paths":        "fact_paths",
+	"gc-packages-time":     "packages",
+	"gc-params-time":       "params",
+	"gc-report-statuses":   "report_statuses",
+}
+
+// storageMeters 存储层计量器 MBean 到指标的映射
+var storageMeters = map[string]storageMetric{
+	"new-catalogs":       {"new_catalogs", "Number of catalogs stored that differ from the previous catalog."},
+	"duplicate-catalogs": {"duplicate_catalogs", "Number of catalogs received that are identical to the stored catalog."},
+}
+
+// StorageMetrics 定义 puppetlabs.puppetdb.storage 中的存储层指标：
+// 计时器输出为摘要，计量器输出为计数器和速率，编录变化量直方图输出为摘要
+type StorageMetrics struct {
+	mu           sync.Mutex
+	duplicatePct float64
+	hasDuplicate bool
+
+	duplicatePctDesc  *prometheus.Desc
+	timers            map[string]*DropwizardMetric
+	meters            map[string]*DropwizardMetric
+	gcTables          *DropwizardMetric
+	catalogVolatility *DropwizardMetric
+}
+
+// NewStorageMetrics 创建存储层指标实例
+func NewStorageMetrics(namespace string) *StorageMetrics {
+	sm := &StorageMetrics{
+		duplicatePctDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "duplicate_percentage"),
+			"Percentage of catalogs that are duplicates.",
+			nil, nil,
+		),
+		timers: make(map[string]*DropwizardMetric),
+		meters: make(map[string]*DropwizardMetric),
+		gcTables: NewDropwizardMetric(DropwizardOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "gc_table_duration",
+			Unit:      "seconds",
+			Help:      "Storage garbage collection duration in seconds, by table.",
+		}, []string{"table"}),
+		catalogVolatility: NewDropwizardMetric(DropwizardOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "catalog_volatility",
+			Help:      "Number of resources and edges changed by each catalog update.",
+		}, nil),
+	}
+
+	for mbean, metric := range storageTimers {
+		sm.timers[mbean] = NewDropwizardMetric(DropwizardOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      metric.name,
+			Unit:      "seconds",
+			Help:      metric.help,
+		}, nil)
+	}
+	for mbean, metric := range storageMeters {
+		sm.meters[mbean] = NewDropwizardMetric(DropwizardOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      metric.name,
+			Help:      metric.help,
+		}, nil)
+	}
+
+	return sm
+}
+
+// Register 注册存储层指标
+func (sm *StorageMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(sm)
+}
+
+// UpdateStorageMetrics 保存最新的 puppetlabs.puppetdb.storage MBean 数据，键为 MBean 的 name 属性
+func (sm *StorageMetrics) UpdateStorageMetrics(data map[string]map[string]interface{}) {
+	for mbean, timer := range sm.timers {
+		timer.Update([]DropwizardSample{{Data: data[mbean]}})
+	}
+	for mbean, meter := range sm.meters {
+		meter.Update([]DropwizardSample{{Data: data[mbean]}})
+	}
+	gcTables := make([]DropwizardSample, 0, len(storageGCTables))
+	for mbean, table := range storageGCTables {
+		gcTables = append(gcTables, DropwizardSample{LabelValues: []string{table}, Data: data[mbean]})
+	}
+	sm.gcTables.Update(gcTables)
+	sm.catalogVolatility.Update([]DropwizardSample{{Data: data["catalog-volitilty"]}})
+
+	value, ok := data["duplicate-pct"]["Value"]
+	sm.mu.Lock()
+	sm.duplicatePct = numberToFloat(value)
+	sm.hasDuplicate = ok
+	sm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (sm *StorageMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sm.duplicatePctDesc
+	for _, timer := range sm.timers {
+		timer.Describe(ch)
+	}
+	for _, meter := range sm.meters {
+		meter.Describe(ch)
+	}
+	sm.gcTables.Describe(ch)
+	sm.catalogVolatility.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector
+func (sm *StorageMetrics) Collect(ch chan<- prometheus.Metric) {
+	sm.mu.Lock()
+	duplicatePct, hasDuplicate := sm.duplicatePct, sm.hasDuplicate
+	sm.mu.Unlock()
+
+	if hasDuplicate {
+		ch <- prometheus.MustNewConstMetric(sm.duplicatePctDesc, prometheus.GaugeValue, duplicatePct)
+	}
+	for _, timer := range sm.timers {
+		timer.Collect(ch)
+	}
+	for _, meter := range sm.meters {
+		meter.Collect(ch)
+	}
+	sm.gcTables.Collect(ch)
+	sm.catalogVolatility.Collect(ch)
+}
@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/camptocamp/prometheus-puppetdb-exporter/internal/puppetdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SummaryStatsMetrics 定义 PuppetDB 汇总统计（/pdb/admin/v1/summary-stats）的指标
+// 汇总统计按较长的间隔获取，收集时输出最近一次成功获取的快照
+type SummaryStatsMetrics struct {
+	mu    sync.Mutex
+	stats *puppetdb.SummaryStats
+
+	tableSizeDesc     *prometheus.Desc
+	tableRowsDesc     *prometheus.Desc
+	tableDeadRowsDesc *prometheus.Desc
+	factPathsDesc     *prometheus.Desc
+	nodeResourcesDesc *prometheus.Desc
+	nodeEdgesDesc     *prometheus.Desc
+}
+
+// NewSummaryStatsMetrics 创建汇总统计指标实例
+func NewSummaryStatsMetrics(namespace string) *SummaryStatsMetrics {
+	return &SummaryStatsMetrics{
+		tableSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "table", "size_bytes"),
+			"Total size of the PuppetDB database table including indexes and TOAST data in bytes.",
+			[]string{"table"}, nil,
+		),
+		tableRowsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "table", "rows"),
+			"Estimated number of live rows in the PuppetDB database table.",
+			[]string{"table"}, nil,
+		),
+		tableDeadRowsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "table", "dead_rows"),
+			"Estimated number of dead rows in the PuppetDB database table.",
+			[]string{"table"}, nil,
+		),
+		factPathsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "fact_paths"),
+			"Number of fact paths stored by PuppetDB, by path depth.",
+			[]string{"depth"}, nil,
+		),
+		nodeResourcesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "node_resources"),
+			"Distribution of the number of resources per node.",
+			[]string{"quantile"}, nil,
+		),
+		nodeEdgesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "node_edges"),
+			"Distribution of the number of resource edges per node.",
+			[]string{"quantile"}, nil,
+		),
+	}
+}
+
+// Register 注册汇总统计指标
+func (sm *SummaryStatsMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(sm)
+}
+
+// UpdateSummaryStats 保存最新的汇总统计
+func (sm *SummaryStatsMetrics) UpdateSummaryStats(stats *puppetdb.SummaryStats) {
+	sm.mu.Lock()
+	sm.stats = stats
+	sm.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector
+func (sm *SummaryStatsMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sm.tableSizeDesc
+	ch <- sm.tableRowsDesc
+	ch <- sm.tableDeadRowsDesc
+	ch <- sm.factPathsDesc
+	ch <- sm.nodeResourcesDesc
+	ch <- sm.nodeEdgesDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (sm *SummaryStatsMetrics) Collect(ch chan<- prometheus.Metric) {
+	sm.mu.Lock()
+	stats := sm.stats
+	sm.mu.Unlock()
+
+	if stats == nil {
+		return
+	}
+
+	for table, size := range stats.TableSizes {
+		ch <- prometheus.MustNewConstMetric(sm.tableSizeDesc, prometheus.GaugeValue, size, table)
+	}
+	for table, rows := range stats.TableRows {
+		ch <- prometheus.MustNewConstMetric(sm.tableRowsDesc, prometheus.GaugeValue, rows, table)
+	}
+	for table, rows := range stats.TableDeadRows {
+		ch <- prometheus.MustNewConstMetric(sm.tableDeadRowsDesc, prometheus.GaugeValue, rows, table)
+	}
+	for depth, count := range stats.FactPaths {
+		ch <- prometheus.MustNewConstMetric(sm.factPathsDesc, prometheus.GaugeValue, count, depth)
+	}
+	for quantile, value := range stats.NodeResources {
+		ch <- prometheus.MustNewConstMetric(sm.nodeResourcesDesc, prometheus.GaugeValue, value, strconv.FormatFloat(quantile, 'g', -1, 64))
+	}
+	for quantile, value := range stats.NodeEdges {
+		ch <- prometheus.MustNewConstMetric(sm.nodeEdgesDesc, prometheus.GaugeValue, value, strconv.FormatFloat(quantile, 'g', -1, 64))
+	}
+}
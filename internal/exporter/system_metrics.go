@@ -1,14 +1,98 @@
 package exporter
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // SystemMetrics 定义系统健康评分相关的指标
 type SystemMetrics struct {
-	healthScore   *prometheus.GaugeVec
-	failureRate   *prometheus.GaugeVec
-	degradedNodes *prometheus.GaugeVec
+	healthScore            *prometheus.GaugeVec
+	failureRate            *prometheus.GaugeVec
+	degradedNodes          *prometheus.GaugeVec
+	environmentHealthScore *prometheus.GaugeVec
+}
+
+// HealthWeights 健康评分中各类节点问题的扣分权重，节点得分为 1 减去命中的权重之和（最低为 0）
+type HealthWeights struct {
+	Failed            float64
+	Unreported        float64
+	NoopPending       float64
+	CachedCatalog     float64
+	CorrectiveChanges float64
+}
+
+// DefaultHealthWeights 默认健康评分权重
+func DefaultHealthWeights() HealthWeights {
+	return HealthWeights{
+		Failed:            1,
+		Unreported:        1,
+		NoopPending:       0.25,
+		CachedCatalog:     0.5,
+		CorrectiveChanges: 0.25,
+	}
+}
+
+// NewHealthWeights 以默认权重为基础，按名称覆盖健康评分权重
+func NewHealthWeights(overrides map[string]float64) (HealthWeights, error) {
+	weights := DefaultHealthWeights()
+	for name, weight := range overrides {
+		// 负权重会让节点得分超过 1
+		if weight < 0 || math.IsNaN(weight) {
+			return weights, fmt.Errorf("invalid health weight %s=%v, must be a non-negative number", name, weight)
+		}
+		switch name {
+		case "failed":
+			weights.Failed = weight
+		case "unreported":
+			weights.Unreported = weight
+		case "noop_pending":
+			weights.NoopPending = weight
+		case "cached_catalog":
+			weights.CachedCatalog = weight
+		case "corrective_changes":
+			weights.CorrectiveChanges = weight
+		default:
+			return weights, fmt.Errorf("unknown health weight %q", name)
+		}
+	}
+	return weights, nil
+}
+
+// NodeHealth 单个活跃节点参与健康评分的状态
+type NodeHealth struct {
+	Environment       string
+	Failed            bool
+	Unreported        bool
+	NoopPending       bool
+	CachedCatalog     bool
+	CorrectiveChanges bool
+}
+
+// Score 按权重计算节点得分（0-1）
+func (nh NodeHealth) Score(weights HealthWeights) float64 {
+	penalty := 0.0
+	if nh.Failed {
+		penalty += weights.Failed
+	}
+	if nh.Unreported {
+		penalty += weights.Unreported
+	}
+	if nh.NoopPending {
+		penalty += weights.NoopPending
+	}
+	if nh.CachedCatalog {
+		penalty += weights.CachedCatalog
+	}
+	if nh.CorrectiveChanges {
+		penalty += weights.CorrectiveChanges
+	}
+	if penalty >= 1 {
+		return 0
+	}
+	return 1 - penalty
 }
 
 // NewSystemMetrics 创建系统指标实例
@@ -19,7 +103,7 @@ func NewSystemMetrics(namespace string) *SystemMetrics {
 		prometheus.GaugeOpts{
 			Namespace: namespace,
 			Name:      "system_health_score",
-			Help:      "PuppetDB system health score (0-100), calculated based on node status",
+			Help:      "PuppetDB system health score (0-100), the mean of active node scores, each node losing the weights of its problems",
 		}, []string{})
 
 	sm.failureRate = prometheus.NewGaugeVec(
@@ -36,44 +120,59 @@ func NewSystemMetrics(namespace string) *SystemMetrics {
 			Help:      "Number of degraded nodes (failed + unreported)",
 		}, []string{})
 
+	sm.environmentHealthScore = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "environment_health_score",
+			Help:      "Health score (0-100) of the active nodes in an environment",
+		}, []string{"environment"})
+
 	return sm
 }
 
 // Register 注册所有系统指标
-func (sm *SystemMetrics) Register() {
-	prometheus.MustRegister(sm.healthScore)
-	prometheus.MustRegister(sm.failureRate)
-	prometheus.MustRegister(sm.degradedNodes)
+func (sm *SystemMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(sm.healthScore)
+	registerer.MustRegister(sm.failureRate)
+	registerer.MustRegister(sm.degradedNodes)
+	registerer.MustRegister(sm.environmentHealthScore)
 }
 
-// UpdateSystemMetrics 更新系统健康评分指标
-func (sm *SystemMetrics) UpdateSystemMetrics(statuses map[string]int) {
-	var totalNodes, healthyNodes, warningNodes, criticalNodes int
-	for _, status := range statuses {
-		totalNodes++
-		switch {
-		case status == 0:
-			healthyNodes++
-		case status > 0 && status <= 2:
-			warningNodes++
-		default:
-			criticalNodes++
+// UpdateSystemMetrics 根据活跃节点的状态更新健康评分指标
+func (sm *SystemMetrics) UpdateSystemMetrics(nodes []NodeHealth, weights HealthWeights) {
+	var totalScore float64
+	var failedNodes, degradedNodes int
+	envScores := make(map[string]float64)
+	envNodes := make(map[string]int)
+
+	for _, node := range nodes {
+		score := node.Score(weights)
+		totalScore += score
+		envScores[node.Environment] += score
+		envNodes[node.Environment]++
+
+		if node.Failed {
+			failedNodes++
+		}
+		if node.Failed || node.Unreported {
+			degradedNodes++
 		}
 	}
 
-	// 健康评分 = (健康节点数 / 总节点数) * 100
-	if totalNodes > 0 {
-		healthScore := (float64(healthyNodes) / float64(totalNodes)) * 100
-		sm.healthScore.WithLabelValues().Set(healthScore)
-	}
+	sm.environmentHealthScore.Reset()
+	// 没有活跃节点时删除评分，避免一直输出上一次的值
+	sm.healthScore.Reset()
+	sm.failureRate.Reset()
+	sm.degradedNodes.Reset()
+	if len(nodes) > 0 {
+		// 健康评分 = 节点得分的平均值 * 100
+		sm.healthScore.WithLabelValues().Set(totalScore / float64(len(nodes)) * 100)
+		// 节点失败率 = (失败节点数 / 总节点数) * 100
+		sm.failureRate.WithLabelValues().Set(float64(failedNodes) / float64(len(nodes)) * 100)
+		sm.degradedNodes.WithLabelValues().Set(float64(degradedNodes))
 
-	// 节点失败率 = (失败节点数 / 总节点数) * 100
-	if totalNodes > 0 {
-		failureRate := (float64(statuses["failed"]) / float64(totalNodes)) * 100
-		sm.failureRate.WithLabelValues().Set(failureRate)
+		for environment, score := range envScores {
+			sm.environmentHealthScore.WithLabelValues(environment).Set(score / float64(envNodes[environment]) * 100)
+		}
 	}
-
-	// 降级节点数 = 失败节点数 + 未报告节点数
-	degradedNodes := statuses["failed"] + statuses["unreported"]
-	sm.degradedNodes.WithLabelValues().Set(float64(degradedNodes))
 }
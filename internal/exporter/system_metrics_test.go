@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHealthWeights(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]float64
+		expected  HealthWeights
+		wantErr   bool
+	}{
+		{name: "默认权重", expected: DefaultHealthWeights()},
+		{
+			name:      "覆盖部分权重",
+			overrides: map[string]float64{"noop_pending": 0, "cached_catalog": 1},
+			expected:  HealthWeights{Failed: 1, Unreported: 1, NoopPending: 0, CachedCatalog: 1, CorrectiveChanges: 0.25},
+		},
+		{name: "未知名称", overrides: map[string]float64{"flapping": 0.5}, wantErr: true},
+		{name: "负权重", overrides: map[string]float64{"failed": -1}, wantErr: true},
+		{name: "NaN", overrides: map[string]float64{"failed": math.NaN()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := NewHealthWeights(tt.overrides)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, weights)
+		})
+	}
+}
+
+func TestNodeHealthScore(t *testing.T) {
+	weights := DefaultHealthWeights()
+
+	tests := []struct {
+		name     string
+		health   NodeHealth
+		expected float64
+	}{
+		{name: "健康", health: NodeHealth{}, expected: 1},
+		{name: "失败", health: NodeHealth{Failed: true}, expected: 0},
+		{name: "未报告", health: NodeHealth{Unreported: true}, expected: 0},
+		{name: "待应用的 noop 变更", health: NodeHealth{NoopPending: true}, expected: 0.75},
+		{name: "多个问题累加", health: NodeHealth{NoopPending: true, CorrectiveChanges: true}, expected: 0.5},
+		{name: "得分最低为 0", health: NodeHealth{Failed: true, CachedCatalog: true}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.health.Score(weights))
+		})
+	}
+}
+
+func TestUpdateSystemMetrics(t *testing.T) {
+	sm := NewSystemMetrics("puppetdb")
+	registry := prometheus.NewRegistry()
+	sm.Register(registry)
+
+	// gauges 返回各指标族的样本值，标签值以 "," 连接
+	gauges := func() map[string]map[string]float64 {
+		families, err := registry.Gather()
+		assert.NoError(t, err)
+		values := make(map[string]map[string]float64)
+		for _, family := range families {
+			values[family.GetName()] = make(map[string]float64)
+			for _, metric := range family.GetMetric() {
+				var labels []string
+				for _, label := range metric.GetLabel() {
+					labels = append(labels, label.GetValue())
+				}
+				values[family.GetName()][strings.Join(labels, ",")] = metric.GetGauge().GetValue()
+			}
+		}
+		return values
+	}
+
+	sm.UpdateSystemMetrics([]NodeHealth{
+		{Environment: "production"},
+		{Environment: "production", Failed: true},
+		{Environment: "staging", Unreported: true},
+		{Environment: "staging", NoopPending: true},
+	}, DefaultHealthWeights())
+	assert.Equal(t, map[string]map[string]float64{
+		"puppetdb_system_health_score":      {"": (1 + 0 + 0 + 0.75) / 4 * 100},
+		"puppetdb_node_failure_rate":        {"": 25},
+		"puppetdb_degraded_nodes":           {"": 2},
+		"puppetdb_environment_health_score": {"production": 50, "staging": 37.5},
+	}, gauges())
+
+	// 没有活跃节点时删除全部评分，而不是输出 0 个降级节点
+	sm.UpdateSystemMetrics(nil, DefaultHealthWeights())
+	assert.Empty(t, gauges())
+}
@@ -0,0 +1,56 @@
+package puppetdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// CAClient Puppet Server CA 客户端，复用 PuppetDB 客户端的 TLS 配置
+type CAClient struct {
+	*PuppetDB
+}
+
+// CertificateStatus certificate_statuses API 返回的证书状态
+type CertificateStatus struct {
+	Name        string   `json:"name"`
+	State       string   `json:"state"`
+	Fingerprint string   `json:"fingerprint"`
+	DNSAltNames []string `json:"dns_alt_names"`
+	NotBefore   string   `json:"not_before"`
+	NotAfter    string   `json:"not_after"`
+}
+
+// caTimeLayouts Puppet CA 返回的时间格式（例如 2024-06-01T12:00:00UTC）
+var caTimeLayouts = []string{
+	"2006-01-02T15:04:05MST",
+	time.RFC3339,
+}
+
+// NewCAClient 创建 Puppet CA 客户端，options.URL 为 Puppet Server 地址（例如 https://puppet:8140）
+func NewCAClient(options *Options) (*CAClient, error) {
+	p, err := NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	return &CAClient{PuppetDB: p}, nil
+}
+
+// CertificateStatuses 获取 CA 中所有证书（包括待签名的 CSR 和已吊销的证书）的状态
+func (ca *CAClient) CertificateStatuses() (statuses []CertificateStatus, err error) {
+	err = ca.get("/puppet-ca/v1/certificate_statuses/any_key", "", &statuses)
+	if err != nil {
+		err = fmt.Errorf("failed to get certificate statuses: %s", err)
+		return
+	}
+	return
+}
+
+// ExpiresAt 解析证书的过期时间
+func (cs CertificateStatus) ExpiresAt() (time.Time, error) {
+	for _, layout := range caTimeLayouts {
+		if t, err := time.Parse(layout, cs.NotAfter); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse certificate expiry %q", cs.NotAfter)
+}
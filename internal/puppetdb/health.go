@@ -0,0 +1,66 @@
+package puppetdb
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// APIHealth 客户端对一个 API 的请求统计
+type APIHealth struct {
+	// Up 最近一次请求是否成功（未发送过请求时为 false）
+	Up bool
+	// Requests 发送的请求数
+	Requests float64
+	// Failures 连接失败或返回 4xx/5xx 的请求数
+	Failures float64
+	// LastSuccess 最近一次成功请求的时间
+	LastSuccess time.Time
+	// LastDuration 最近一次请求的耗时
+	LastDuration time.Duration
+}
+
+// apiHealth 记录请求统计，抓取循环写入，收集指标时读取
+type apiHealth struct {
+	mu     sync.Mutex
+	health APIHealth
+}
+
+// record 记录一次请求的结果
+func (h *apiHealth) record(err error, duration time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.health.Requests++
+	h.health.LastDuration = duration
+	h.health.Up = err == nil
+	if err != nil {
+		h.health.Failures++
+		return
+	}
+	h.health.LastSuccess = time.Now()
+}
+
+// Health 返回客户端的请求统计
+func (p *PuppetDB) Health() APIHealth {
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+	return p.health.health
+}
+
+// do 发送请求，附加认证令牌并记录请求统计
+func (p *PuppetDB) do(req *http.Request) (*http.Response, error) {
+	if p.token != "" {
+		req.Header.Set("X-Authentication", p.token)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	result := err
+	if err == nil && resp.StatusCode >= 400 {
+		result = fmt.Errorf("status %d", resp.StatusCode)
+	}
+	p.health.record(result, time.Since(start))
+	return resp, err
+}
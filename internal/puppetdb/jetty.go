@@ -0,0 +1,184 @@
+package puppetdb
+
+import (
+	"strings"
+)
+
+// JettyThreadPool Jetty 线程池（QueuedThreadPool）的状态，未提供的值为 -1
+type JettyThreadPool struct {
+	Name        string
+	Threads     float64
+	IdleThreads float64
+	BusyThreads float64
+	MaxThreads  float64
+	QueueSize   float64
+}
+
+// JettyConnector Jetty 连接器的连接和流量统计，同名连接器的值会累加，未提供的值为 -1
+// 流量统计只在 Jetty 配置了 ConnectionStatistics 时提供
+type JettyConnector struct {
+	Name             string
+	OpenConnections  float64
+	ConnectionsTotal float64
+	ReceivedBytes    float64
+	SentBytes        float64
+}
+
+// JettyContext StatisticsHandler 的请求统计，Name 为 MBean 的 context 属性，没有时为 "all"
+// RequestTime 单位为秒，Responses 以状态码类别（1xx-5xx）为键，未提供的值为 -1
+type JettyContext struct {
+	Name           string
+	Requests       float64
+	RequestsActive float64
+	RequestTime    float64
+	ResponseBytes  float64
+	Responses      map[string]float64
+}
+
+// JettyStats PuppetDB 内置 Jetty Web 服务器的线程池、连接器和请求统计
+type JettyStats struct {
+	ThreadPools []JettyThreadPool
+	Connectors  []JettyConnector
+	Contexts    []JettyContext
+}
+
+// jettyResponseClasses StatisticsHandler 的响应计数属性到状态码类别的映射
+var jettyResponseClasses = map[string]string{
+	"responses1xx": "1xx",
+	"responses2xx": "2xx",
+	"responses3xx": "3xx",
+	"responses4xx": "4xx",
+	"responses5xx": "5xx",
+}
+
+// jettyReadRequests Jetty 的线程池、连接器和请求统计的读取请求
+// ConnectionStatistics 和 StatisticsHandler 是可选组件，只在 MBean 列表中存在时读取，避免每次抓取都产生读取错误
+func jettyReadRequests(mbeans []string) []ReadRequest {
+	requests := []ReadRequest{
+		NewReadRequest("org.eclipse.jetty.util.thread:type=queuedthreadpool,*", "threads", "idleThreads", "busyThreads", "maxThreads", "queueSize"),
+		NewReadRequest("org.eclipse.jetty.io:type=managedselector,*", "totalKeys"),
+	}
+	if hasMBeanType(mbeans, "org.eclipse.jetty.io:", "connectionstatistics") {
+		requests = append(requests, NewReadRequest("org.eclipse.jetty.io:type=connectionstatistics,*", "connectionsTotal", "receivedBytes", "sentBytes"))
+	}
+	if hasMBeanType(mbeans, "org.eclipse.jetty.server.handler:", "statisticshandler") {
+		requests = append(requests, NewReadRequest("org.eclipse.jetty.server.handler:type=statisticshandler,*", "requests", "requestsActive", "requestTimeTotal", "responsesBytesTotal", "responses1xx", "responses2xx", "responses3xx", "responses4xx", "responses5xx"))
+	}
+	return requests
+}
+
+// GetJettyMetrics 读取 Jetty 的线程池、连接器和请求统计
+func (s *MetricsSnapshot) GetJettyMetrics() (*JettyStats, error) {
+	data, err := s.result("jetty")
+	if data == nil {
+		return nil, err
+	}
+
+	field := func(attributes map[string]interface{}, name string) float64 {
+		if value, ok := s.mc.mbeanField(attributes, name); ok {
+			return value
+		}
+		return -1
+	}
+
+	stats := &JettyStats{}
+	connectors := make(map[string]*JettyConnector)
+	connector := func(mbean string) *JettyConnector {
+		name := jettyConnectorName(mbeanProperty(mbean, "context"))
+		if connectors[name] == nil {
+			connectors[name] = &JettyConnector{Name: name, OpenConnections: -1, ConnectionsTotal: -1, ReceivedBytes: -1, SentBytes: -1}
+		}
+		return connectors[name]
+	}
+
+	for mbean, attributes := range data {
+		if !strings.HasPrefix(mbean, "org.eclipse.jetty.") {
+			continue
+		}
+		switch mbeanProperty(mbean, "type") {
+		case "queuedthreadpool":
+			stats.ThreadPools = append(stats.ThreadPools, JettyThreadPool{
+				Name:        mbeanProperty(mbean, "id"),
+				Threads:     field(attributes, "threads"),
+				IdleThreads: field(attributes, "idleThreads"),
+				BusyThreads: field(attributes, "busyThreads"),
+				MaxThreads:  field(attributes, "maxThreads"),
+				QueueSize:   field(attributes, "queueSize"),
+			})
+		case "managedselector":
+			// 每个选择器中注册的键对应一个打开的连接
+			c := connector(mbean)
+			c.OpenConnections = addJettyValue(c.OpenConnections, field(attributes, "totalKeys"))
+		case "connectionstatistics":
+			c := connector(mbean)
+			c.ConnectionsTotal = addJettyValue(c.ConnectionsTotal, field(attributes, "connectionsTotal"))
+			c.ReceivedBytes = addJettyValue(c.ReceivedBytes, field(attributes, "receivedBytes"))
+			c.SentBytes = addJettyValue(c.SentBytes, field(attributes, "sentBytes"))
+		case "statisticshandler":
+			context := JettyContext{
+				Name:           mbeanProperty(mbean, "context"),
+				Requests:       field(attributes, "requests"),
+				RequestsActive: field(attributes, "requestsActive"),
+				RequestTime:    field(attributes, "requestTimeTotal"),
+				ResponseBytes:  field(attributes, "responsesBytesTotal"),
+				Responses:      make(map[string]float64),
+			}
+			if context.Name == "" {
+				context.Name = "all"
+			}
+			if context.RequestTime >= 0 {
+				context.RequestTime /= 1000
+			}
+			for attribute, class := range jettyResponseClasses {
+				if value := field(attributes, attribute); value >= 0 {
+					context.Responses[class] = value
+				}
+			}
+			stats.Contexts = append(stats.Contexts, context)
+		}
+	}
+	for _, c := range connectors {
+		stats.Connectors = append(stats.Connectors, *c)
+	}
+
+	return stats, err
+}
+
+// jettyConnectorName 将带实例哈希的连接器名称转换为稳定的标签值
+// 例如 "SSL_HTTP_1_1@b305a16" 为 "https"，"HTTP_1_1@258c34f" 为 "http"
+func jettyConnectorName(context string) string {
+	if i := strings.Index(context, "@"); i >= 0 {
+		context = context[:i]
+	}
+	upper := strings.ToUpper(context)
+	switch {
+	case strings.HasPrefix(upper, "SSL"):
+		return "https"
+	case strings.HasPrefix(upper, "HTTP"):
+		return "http"
+	case context == "":
+		return "unknown"
+	}
+	return normalizeLabelValue(context)
+}
+
+// hasMBeanType 判断 MBean 列表中是否存在指定域和类型的 MBean
+func hasMBeanType(mbeans []string, domain string, mbeanType string) bool {
+	for _, mbean := range mbeans {
+		if strings.HasPrefix(mbean, domain) && mbeanProperty(mbean, "type") == mbeanType {
+			return true
+		}
+	}
+	return false
+}
+
+// addJettyValue 累加同名连接器的值，-1 表示未提供
+func addJettyValue(total float64, value float64) float64 {
+	if value < 0 {
+		return total
+	}
+	if total < 0 {
+		return value
+	}
+	return total + value
+}
@@ -0,0 +1,270 @@
+package puppetdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReadRequest /metrics/v2/read 的单个 Jolokia 读取请求
+// MBean 可以是通配符模式（例如 puppetlabs.puppetdb.mq:name=*），Attribute 为空时读取全部属性
+type ReadRequest struct {
+	Type      string   `json:"type"`
+	MBean     string   `json:"mbean"`
+	Attribute []string `json:"attribute,omitempty"`
+	// Config Jolokia 处理参数，例如 ignoreErrors 使缺失的属性不会导致整个请求失败
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// ExecRequest /metrics/v2 的单个 Jolokia 操作调用请求
+// 重载的操作需要在 Operation 中给出签名，例如 "getThreadInfo([J)"
+type ExecRequest struct {
+	Type      string        `json:"type"`
+	MBean     string        `json:"mbean"`
+	Operation string        `json:"operation"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+// readResponse 批量读取中单个请求的响应
+type readResponse struct {
+	Status int         `json:"status"`
+	Value  interface{} `json:"value"`
+	Error  string      `json:"error"`
+}
+
+// ReadError 批量读取中部分请求失败，Failed 以请求的 MBean 为键保存错误信息
+type ReadError struct {
+	Failed map[string]string
+}
+
+func (e *ReadError) Error() string {
+	mbeans := make([]string, 0, len(e.Failed))
+	for mbean := range e.Failed {
+		mbeans = append(mbeans, mbean)
+	}
+	sort.Strings(mbeans)
+
+	details := make([]string, len(mbeans))
+	for i, mbean := range mbeans {
+		details[i] = fmt.Sprintf("%s: %s", mbean, e.Failed[mbean])
+	}
+	return fmt.Sprintf("failed to read %d mbeans: %s", len(mbeans), strings.Join(details, "; "))
+}
+
+// NewReadRequest 创建读取请求，attributes 为空时读取全部属性
+// 指定属性时忽略单个属性的读取错误（不同 JVM 版本提供的属性不同）
+func NewReadRequest(mbean string, attributes ...string) ReadRequest {
+	request := ReadRequest{Type: "read", MBean: mbean, Attribute: attributes}
+	if len(attributes) > 0 {
+		request.Config = map[string]interface{}{"ignoreErrors": true}
+	}
+	return request
+}
+
+// isMBeanPattern 判断 MBean 名称是否为通配符模式
+func isMBeanPattern(mbean string) bool {
+	return strings.ContainsAny(mbean, "*?")
+}
+
+// addReadData 记录 MBean 的属性，同一 MBean 出现在多个请求（例如通配符和显式名称）中时合并属性
+func addReadData(data map[string]map[string]interface{}, mbean string, attributes map[string]interface{}) {
+	existing, ok := data[mbean]
+	if !ok {
+		data[mbean] = attributes
+		return
+	}
+	for attribute, value := range attributes {
+		existing[attribute] = value
+	}
+}
+
+// mbeanProperty 返回 MBean 名称中指定键的属性值，例如 "java.lang:name=G1 Old Gen,type=MemoryPool" 中 name 的值
+func mbeanProperty(mbean string, key string) string {
+	i := strings.Index(mbean, ":")
+	if i < 0 {
+		return ""
+	}
+	for _, property := range strings.Split(mbean[i+1:], ",") {
+		if strings.HasPrefix(property, key+"=") {
+			return strings.TrimPrefix(property, key+"=")
+		}
+	}
+	return ""
+}
+
+// normalizeLabelValue 将 MBean 属性值转换为稳定的标签值：转换为小写，非字母数字字符替换为下划线
+func normalizeLabelValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(value))
+	for strings.Contains(value, "__") {
+		value = strings.ReplaceAll(value, "__", "_")
+	}
+	return strings.Trim(value, "_")
+}
+
+// jolokiaBackend 通过 /metrics/v2（Jolokia）访问 MBean
+type jolokiaBackend struct {
+	*PuppetDB
+}
+
+// Name 实现 MetricsBackend
+func (jb *jolokiaBackend) Name() string {
+	return "v2"
+}
+
+// available 通过 /metrics/v2/version 判断 Jolokia 是否可用
+func (jb *jolokiaBackend) available() error {
+	var version readResponse
+	if err := jb.get("/metrics/v2/version", "", &version); err != nil {
+		return err
+	}
+	if version.Status != 200 {
+		return fmt.Errorf("jolokia version request returned status %d: %s", version.Status, version.Error)
+	}
+	return nil
+}
+
+// List 实现 MetricsBackend，从 /metrics/v2/list 构建 "域:属性" 形式的 MBean 名称
+func (jb *jolokiaBackend) List() ([]string, error) {
+	var result map[string]interface{}
+	err := jb.get("/metrics/v2/list", "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析嵌套的JSON结构，提取MBean名称
+	var mbeans []string
+
+	// 检查value字段是否存在
+	if value, ok := result["value"]; ok {
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			// 遍历value中的每个MBean及其属性
+			for mbeanName, attributes := range valueMap {
+				if attrsMap, ok := attributes.(map[string]interface{}); ok {
+					// 遍历所有属性，构建完整的MBean名称
+					for attrKey := range attrsMap {
+						if attrKey == "" {
+							// 空属性，只使用主名称
+							mbeans = append(mbeans, mbeanName)
+						} else {
+							// 非空属性，组合成完整的MBean名称
+							fullMBeanName := fmt.Sprintf("%s:%s", mbeanName, attrKey)
+							mbeans = append(mbeans, fullMBeanName)
+						}
+					}
+				} else {
+					// 如果属性不是map类型，只添加主MBean名称
+					mbeans = append(mbeans, mbeanName)
+				}
+			}
+		}
+	} else {
+		// 如果没有value字段，直接遍历顶层对象（兼容旧格式）
+		for mbeanName := range result {
+			// 跳过request字段，只处理MBean名称
+			if mbeanName != "request" {
+				mbeans = append(mbeans, mbeanName)
+			}
+		}
+	}
+
+	return mbeans, nil
+}
+
+// Read 实现 MetricsBackend，以 /metrics/v2/read 批量请求执行多个读取
+func (jb *jolokiaBackend) Read(requests ...ReadRequest) (map[string]map[string]interface{}, error) {
+	data := make(map[string]map[string]interface{})
+	failed := make(map[string]string)
+
+	for start := 0; start < len(requests); start += mbeanReadBatchSize {
+		end := start + mbeanReadBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		batch := requests[start:end]
+
+		responses, err := jb.bulkRequest("/metrics/v2/read", batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, response := range responses {
+			if i >= len(batch) {
+				break
+			}
+			mbean := batch[i].MBean
+			if response.Status != 200 {
+				failed[mbean] = fmt.Sprintf("status %d: %s", response.Status, response.Error)
+				continue
+			}
+			value, ok := response.Value.(map[string]interface{})
+			if !ok {
+				failed[mbean] = "unexpected value"
+				continue
+			}
+			if !isMBeanPattern(mbean) {
+				addReadData(data, mbean, value)
+				continue
+			}
+			for name, attributes := range value {
+				if attributesMap, ok := attributes.(map[string]interface{}); ok {
+					addReadData(data, name, attributesMap)
+				}
+			}
+		}
+		if len(responses) < len(batch) {
+			for _, request := range batch[len(responses):] {
+				failed[request.MBean] = "missing response"
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return data, &ReadError{Failed: failed}
+	}
+	return data, nil
+}
+
+// Exec 实现 MetricsBackend，通过 /metrics/v2/exec 调用操作，需要 Jolokia 访问策略允许该操作
+func (jb *jolokiaBackend) Exec(mbean string, operation string, arguments ...interface{}) (interface{}, error) {
+	responses, err := jb.bulkRequest("/metrics/v2/exec", []ExecRequest{{Type: "exec", MBean: mbean, Operation: operation, Arguments: arguments}})
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("failed to exec %s on %s: missing response", operation, mbean)
+	}
+	if responses[0].Status != 200 {
+		return nil, fmt.Errorf("failed to exec %s on %s: status %d: %s", operation, mbean, responses[0].Status, responses[0].Error)
+	}
+	return responses[0].Value, nil
+}
+
+// bulkRequest 向 endpoint 发送一个 Jolokia 批量请求，requests 为请求数组
+func (jb *jolokiaBackend) bulkRequest(endpoint string, requests interface{}) ([]readResponse, error) {
+	requestBody, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal jolokia request: %s", err)
+	}
+
+	resp, err := jb.Post(endpoint, "application/json", requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to send jolokia request: status %d", resp.StatusCode)
+	}
+
+	var responses []readResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		return nil, fmt.Errorf("failed to decode jolokia response: %s", err)
+	}
+	return responses, nil
+}
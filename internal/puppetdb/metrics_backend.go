@@ -0,0 +1,117 @@
+package puppetdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// metricsBackendTTL 重新检测指标 API 的间隔，PuppetDB 修改配置后无需重启导出器
+const metricsBackendTTL = 10 * time.Minute
+
+// metricsBackendRetry 没有可用的指标 API 时重新检测的间隔
+const metricsBackendRetry = time.Minute
+
+// MetricsBackend PuppetDB 指标 API 的访问方式，MetricsClient 的所有 MBean 读取都通过它完成
+type MetricsBackend interface {
+	// Name 返回后端名称："v2"（/metrics/v2，Jolokia）或 "v1"（/metrics/v1/mbeans）
+	Name() string
+	// List 返回所有可用的 MBean 名称
+	List() ([]string, error)
+	// Read 执行多个读取，返回以完整 MBean 名称为键的属性数据，模式读取的结果会展开为匹配的各个 MBean
+	// 部分请求失败时返回已读取的数据和 *ReadError；整个请求失败时只返回错误
+	Read(requests ...ReadRequest) (map[string]map[string]interface{}, error)
+	// Exec 调用 MBean 的操作并返回结果
+	Exec(mbean string, operation string, arguments ...interface{}) (interface{}, error)
+}
+
+// detectMetricsBackend 检测 PuppetDB 提供的指标 API，优先使用 /metrics/v2，
+// Jolokia 被禁用或版本过旧时回退到 /metrics/v1/mbeans
+func (mc *MetricsClient) detectMetricsBackend() (MetricsBackend, error) {
+	jolokia := &jolokiaBackend{PuppetDB: mc.PuppetDB}
+	jolokiaErr := jolokia.available()
+	if jolokiaErr == nil {
+		return jolokia, nil
+	}
+
+	legacy := &legacyBackend{PuppetDB: mc.PuppetDB, list: mc.cachedMBeans}
+	mbeans, legacyErr := legacy.List()
+	if legacyErr == nil && len(mbeans) > 0 {
+		return legacy, nil
+	}
+	if legacyErr == nil {
+		legacyErr = fmt.Errorf("no mbeans listed")
+	}
+	return nil, fmt.Errorf("no metrics API available: /metrics/v2: %s; /metrics/v1: %s", jolokiaErr, legacyErr)
+}
+
+// Backend 返回当前使用的指标 API，首次调用、超过检测间隔或 PuppetDB 版本变化后重新检测
+func (mc *MetricsClient) Backend() (MetricsBackend, error) {
+	retry := metricsBackendTTL
+	if mc.backend == nil {
+		retry = metricsBackendRetry
+	}
+	if !mc.backendCheckedAt.IsZero() && time.Since(mc.backendCheckedAt) < retry {
+		if mc.backend == nil {
+			return nil, mc.backendErr
+		}
+		return mc.backend, nil
+	}
+
+	backend, err := mc.detectMetricsBackend()
+	mc.backendCheckedAt = time.Now()
+	mc.backendErr = err
+	if backendName(backend) != backendName(mc.backend) {
+		// 不同 API 列出的 MBean 名称格式可能不同
+		mc.mbeans = nil
+	}
+	mc.backend = backend
+	return backend, err
+}
+
+// BackendName 返回当前使用的指标 API 名称，没有可用的 API 时为 "none"
+func (mc *MetricsClient) BackendName() string {
+	return backendName(mc.backend)
+}
+
+// ObserveVersion 记录 PuppetDB 版本，版本变化（例如升级）时在下次读取前重新检测指标 API
+func (mc *MetricsClient) ObserveVersion(version string) {
+	if mc.serverVersion != "" && version != mc.serverVersion {
+		mc.backendCheckedAt = time.Time{}
+	}
+	mc.serverVersion = version
+}
+
+// Read 通过当前的指标 API 执行多个读取，参见 MetricsBackend.Read
+func (mc *MetricsClient) Read(requests ...ReadRequest) (map[string]map[string]interface{}, error) {
+	backend, err := mc.Backend()
+	if err != nil {
+		return nil, err
+	}
+	return backend.Read(requests...)
+}
+
+// Exec 通过当前的指标 API 调用 MBean 的操作
+func (mc *MetricsClient) Exec(mbean string, operation string, arguments ...interface{}) (interface{}, error) {
+	backend, err := mc.Backend()
+	if err != nil {
+		return nil, err
+	}
+	return backend.Exec(mbean, operation, arguments...)
+}
+
+// GetAvailableMBeans 获取所有可用的MBean列表
+func (mc *MetricsClient) GetAvailableMBeans() ([]string, error) {
+	backend, err := mc.Backend()
+	if err != nil {
+		return nil, err
+	}
+	return backend.List()
+}
+
+// backendName 返回指标 API 的名称，backend 为 nil 时为 "none"
+func backendName(backend MetricsBackend) string {
+	if backend == nil {
+		return "none"
+	}
+	return backend.Name()
+}
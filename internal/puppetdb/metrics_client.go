@@ -1,15 +1,51 @@
 package puppetdb
 
 import (
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// mbeanListTTL MBean 列表的缓存时间，避免每次抓取都请求 /metrics/v2/list
+const mbeanListTTL = 5 * time.Minute
+
+// mbeanReadBatchSize 每个批量读取请求包含的 MBean 数量上限
+const mbeanReadBatchSize = 100
+
 // MetricsClient 扩展的PuppetDB客户端，专门用于获取指标
 type MetricsClient struct {
 	*PuppetDB
+
+	mbeans          []string
+	mbeansFetchedAt time.Time
+
+	backend          MetricsBackend
+	backendErr       error
+	backendCheckedAt time.Time
+	serverVersion    string
+}
+
+// CommandMBean 单个命令版本的消息队列 MBean 数据
+type CommandMBean struct {
+	Command string
+	Version string
+	Metric  string
+	Data    map[string]interface{}
+}
+
+// commandMBeanMetrics 按命令导出的消息队列指标
+var commandMBeanMetrics = map[string]bool{
+	"processed":                true,
+	"fatal":                    true,
+	"retried":                  true,
+	"discarded":                true,
+	"ignored":                  true,
+	"awaiting-retry":           true,
+	"depth":                    true,
+	"queue-time":               true,
+	"processing-time":          true,
+	"message-persistence-time": true,
 }
 
 // NewMetricsClient 创建指标客户端
@@ -17,749 +53,683 @@ func NewMetricsClient(pdb *PuppetDB) *MetricsClient {
 	return &MetricsClient{PuppetDB: pdb}
 }
 
-// GetPopulationMetrics 获取人口统计指标
-func (mc *MetricsClient) GetPopulationMetrics() (map[string]float64, error) {
-	metrics := make(map[string]float64)
-
-	// 获取节点数量
-	nodeCount, err := mc.getMBeanValue("puppetlabs.puppetdb.population:name=num-nodes")
-	if err == nil {
-		metrics["nodes"] = nodeCount
-	}
+// 各组指标 MBean 名称的前缀，以通配符读取前缀下的全部 MBean
+const (
+	populationPrefix = "puppetlabs.puppetdb.population:name="
+	storagePrefix    = "puppetlabs.puppetdb.storage:name="
+	adminPrefix      = "puppetlabs.puppetdb.admin:name="
+	dloPrefix        = "puppetlabs.puppetdb.dlo:name="
+	databasePrefix   = "puppetlabs.puppetdb.database:name="
+	httpPrefix       = "puppetlabs.puppetdb.http:name="
+)
 
-	// 获取资源数量
-	resourceCount, err := mc.getMBeanValue("puppetlabs.puppetdb.population:name=num-resources")
-	if err == nil {
-		metrics["resources"] = resourceCount
-	}
+// populationMBeans 人口统计 MBean 名称到指标键的映射
+var populationMBeans = map[string]string{
+	"num-nodes":              "nodes",
+	"num-resources":          "resources",
+	"avg-resources-per-node": "avg_resources_per_node",
+	"pct-resource-dupes":     "resource_duplicates_pct",
+}
 
-	// 获取平均资源数
-	avgResources, err := mc.getMBeanValue("puppetlabs.puppetdb.population:name=avg-resources-per-node")
-	if err == nil {
-		metrics["avg_resources_per_node"] = avgResources
+// GetPopulationMetrics 获取人口统计指标
+func (s *MetricsSnapshot) GetPopulationMetrics() (map[string]float64, error) {
+	data, err := s.result("population")
+	if data == nil {
+		return nil, err
 	}
 
-	// 获取重复资源百分比
-	duplicatePct, err := mc.getMBeanValue("puppetlabs.puppetdb.population:name=pct-resource-dupes")
-	if err == nil {
-		metrics["resource_duplicates_pct"] = duplicatePct
+	metrics := make(map[string]float64)
+	for name, key := range populationMBeans {
+		if value, ok := s.mc.mbeanValue(data[populationPrefix+name]); ok {
+			metrics[key] = value
+		}
 	}
-
-	return metrics, nil
+	return metrics, err
 }
 
-// GetStorageMetrics 获取存储层指标
-func (mc *MetricsClient) GetStorageMetrics() (map[string]float64, error) {
-	metrics := make(map[string]float64)
+// GetStorageMetrics 以通配符读取存储层的计时器、计量器和直方图 MBean，键为 MBean 的 name 属性
+func (s *MetricsSnapshot) GetStorageMetrics() (map[string]map[string]interface{}, error) {
+	return s.namedMBeans("storage", storagePrefix)
+}
 
-	// 获取重复编录百分比
-	duplicatePct, err := mc.getMBeanValue("puppetlabs.puppetdb.storage:name=duplicate-pct")
-	if err == nil {
-		metrics["duplicate_pct"] = duplicatePct
+// commandReadRequests 按命令的消息队列 MBean 的读取请求
+// 命令列表从 MBean 列表中发现，只读取导出的指标，不读取 global.* 和其他 mq MBean
+func commandReadRequests(mbeans []string) []ReadRequest {
+	var requests []ReadRequest
+	for _, mbean := range mbeans {
+		if _, _, metric, ok := parseCommandMBean(mbean); ok && commandMBeanMetrics[metric] {
+			requests = append(requests, NewReadRequest(mbean))
+		}
 	}
+	return requests
+}
 
-	// 获取GC时间
-	gcTime, err := mc.getMBeanValue("puppetlabs.puppetdb.storage:name=gc-time")
-	if err == nil {
-		metrics["gc_time"] = gcTime
+// GetPerCommandMetrics 读取按命令和版本划分的消息队列 MBean
+func (s *MetricsSnapshot) GetPerCommandMetrics() ([]CommandMBean, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
 	}
-
-	// 获取替换事实时间
-	replaceFactsTime, err := mc.getMBeanValue("puppetlabs.puppetdb.storage:name=replace-facts-time")
-	if err == nil {
-		metrics["replace_facts_time"] = replaceFactsTime
+	if len(s.requests["commands"]) == 0 {
+		return nil, nil
 	}
 
-	// 获取替换编录时间
-	replaceCatalogTime, err := mc.getMBeanValue("puppetlabs.puppetdb.storage:name=replace-catalog-time")
-	if err == nil {
-		metrics["replace_catalog_time"] = replaceCatalogTime
+	data, err := s.result("commands")
+	if data == nil {
+		return nil, err
 	}
 
-	return metrics, nil
-}
-
-// GetCommandMetrics 获取命令处理指标
-func (mc *MetricsClient) GetCommandMetrics() (map[string]map[string]float64, error) {
-	metrics := make(map[string]map[string]float64)
-
-	// 获取全局命令指标
-	globalMetrics := []string{"seen", "processed", "fatal", "retried", "awaiting-retry", "depth"}
-	for _, metric := range globalMetrics {
-		value, err := mc.getMBeanValue(fmt.Sprintf("puppetlabs.puppetdb.mq:name=global.%s", metric))
-		if err == nil {
-			if metrics["global"] == nil {
-				metrics["global"] = make(map[string]float64)
-			}
-			metrics["global"][metric] = value
+	var commands []CommandMBean
+	for mbean, attributes := range data {
+		command, version, metric, ok := parseCommandMBean(mbean)
+		if !ok || !commandMBeanMetrics[metric] {
+			continue
 		}
+		commands = append(commands, CommandMBean{
+			Command: command,
+			Version: version,
+			Metric:  metric,
+			Data:    attributes,
+		})
 	}
 
-	return metrics, nil
+	return commands, err
 }
 
-// GetDBMetrics 获取数据库连接池指标
-func (mc *MetricsClient) GetDBMetrics() (map[string]map[string]float64, error) {
-	metrics := make(map[string]map[string]float64)
-
-	// HikariCP指标映射
-	hikariMetrics := []string{"ActiveConnections", "IdleConnections", "TotalConnections", "WaitTime"}
-	pools := []string{"PDBReadPool", "PDBWritePool"}
+// parseCommandMBean 解析形如 "puppetlabs.puppetdb.mq:name=replace catalog.9.processing-time" 的 MBean 名称
+func parseCommandMBean(mbean string) (command string, version string, metric string, ok bool) {
+	const prefix = "puppetlabs.puppetdb.mq:name="
+	if !strings.HasPrefix(mbean, prefix) {
+		return
+	}
+	name := strings.TrimPrefix(mbean, prefix)
 
-	for _, pool := range pools {
-		metrics[pool] = make(map[string]float64)
-		for _, metric := range hikariMetrics {
-			value, err := mc.getMBeanValue(fmt.Sprintf("puppetlabs.puppetdb.database:%s.%s", pool, metric))
-			if err == nil {
-				metrics[pool][metric] = value
-			}
-		}
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return
+	}
+	metric = name[i+1:]
+	name = name[:i]
 
-		// 获取待处理连接数
-		pendingValue, err := mc.getMBeanValue(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.PendingConnections", pool))
-		if err == nil {
-			metrics[pool]["PendingConnections"] = pendingValue
-		}
+	i = strings.LastIndex(name, ".")
+	if i < 0 {
+		return
+	}
+	version = name[i+1:]
+	command = name[:i]
+	if _, err := strconv.Atoi(version); err != nil || command == "" {
+		return
+	}
 
-		// 获取连接池配置指标
-		maxConnections, err := mc.getMBeanValue(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.MaxConnections", pool))
-		if err == nil {
-			metrics[pool]["MaxConnections"] = maxConnections
-		}
+	ok = true
+	return
+}
 
-		minConnections, err := mc.getMBeanValue(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.MinConnections", pool))
-		if err == nil {
-			metrics[pool]["MinConnections"] = minConnections
-		}
+// cachedMBeans 返回缓存的 MBean 列表，缓存过期时重新获取
+func (mc *MetricsClient) cachedMBeans() ([]string, error) {
+	if mc.mbeans != nil && time.Since(mc.mbeansFetchedAt) < mbeanListTTL {
+		return mc.mbeans, nil
 	}
 
-	return metrics, nil
+	mbeans, err := mc.GetAvailableMBeans()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mbeans: %s", err)
+	}
+	mc.mbeans = mbeans
+	mc.mbeansFetchedAt = time.Now()
+	return mbeans, nil
 }
 
-// GetDBPoolUsageMetrics 获取数据库连接池使用统计指标
-func (mc *MetricsClient) GetDBPoolUsageMetrics() (map[string]map[string]float64, error) {
-	metrics := make(map[string]map[string]float64)
-	pools := []string{"PDBReadPool", "PDBWritePool"}
+// ListMBeans 返回 /metrics/v2/list 中的 MBean 列表（缓存 5 分钟）
+func (mc *MetricsClient) ListMBeans() ([]string, error) {
+	return mc.cachedMBeans()
+}
 
-	for _, pool := range pools {
-		metrics[pool] = make(map[string]float64)
+// GetAdminMetrics 以通配符读取定期清理操作（过期、清除和垃圾回收）的 MBean，键为 MBean 的 name 属性
+func (s *MetricsSnapshot) GetAdminMetrics() (map[string]map[string]interface{}, error) {
+	return s.namedMBeans("admin", adminPrefix)
+}
 
-		// 获取Usage统计
-		usageResult, err := mc.getMBeanFullData(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.Usage", pool))
-		if err == nil && usageResult != nil {
-			// 提取关键百分位数和统计信息
-			if val, ok := usageResult["Mean"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["UsageMean"] = floatVal
-				}
-			}
-			if val, ok := usageResult["75thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["Usage75thPercentile"] = floatVal
-				}
-			}
-			if val, ok := usageResult["95thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["Usage95thPercentile"] = floatVal
-				}
-			}
-			if val, ok := usageResult["99thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["Usage99thPercentile"] = floatVal
-				}
-			}
-			if val, ok := usageResult["Max"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["UsageMax"] = floatVal
-				}
-			}
-		}
+// GetDLOMetrics 以通配符读取死信队列（dead letter office）的 MBean，
+// 按命令（全局为 "global"）和指标（messages、filesize）分组
+func (s *MetricsSnapshot) GetDLOMetrics() (map[string]map[string]float64, error) {
+	const prefix = "puppetlabs.puppetdb.dlo."
 
-		// 获取Wait统计
-		waitResult, err := mc.getMBeanFullData(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.Wait", pool))
-		if err == nil && waitResult != nil {
-			// 提取关键百分位数和统计信息
-			if val, ok := waitResult["Mean"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["WaitMean"] = floatVal / 1000.0 // 转换为秒
-				}
-			}
-			if val, ok := waitResult["75thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["Wait75thPercentile"] = floatVal / 1000.0 // 转换为秒
-				}
-			}
-			if val, ok := waitResult["95thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["Wait95thPercentile"] = floatVal / 1000.0 // 转换为秒
-				}
-			}
-			if val, ok := waitResult["99thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["Wait99thPercentile"] = floatVal / 1000.0 // 转换为秒
-				}
-			}
-			if val, ok := waitResult["Max"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["WaitMax"] = floatVal / 1000.0 // 转换为秒
-				}
-			}
-		}
+	data, err := s.namedMBeans("dlo", dloPrefix)
+	if data == nil {
+		return nil, err
 	}
 
-	return metrics, nil
-}
-
-// GetDBPoolConnectionCreationMetrics 获取数据库连接池连接创建统计指标
-func (mc *MetricsClient) GetDBPoolConnectionCreationMetrics() (map[string]map[string]float64, error) {
 	metrics := make(map[string]map[string]float64)
-	pools := []string{"PDBReadPool", "PDBWritePool"}
-
-	for _, pool := range pools {
-		metrics[pool] = make(map[string]float64)
-
-		// 获取ConnectionCreation统计
-		creationResult, err := mc.getMBeanFullData(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.ConnectionCreation", pool))
-		if err == nil && creationResult != nil {
-			// 提取关键百分位数和统计信息
-			if val, ok := creationResult["Mean"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionCreationMean"] = floatVal
-				}
-			}
-			if val, ok := creationResult["75thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionCreation75thPercentile"] = floatVal
-				}
-			}
-			if val, ok := creationResult["95thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionCreation95thPercentile"] = floatVal
-				}
-			}
-			if val, ok := creationResult["99thPercentile"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionCreation99thPercentile"] = floatVal
-				}
-			}
-			if val, ok := creationResult["Max"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionCreationMax"] = floatVal
-				}
-			}
-			if val, ok := creationResult["Count"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionCreationCount"] = floatVal
-				}
-			}
+	for name, attributes := range data {
+		i := strings.LastIndex(name, ".")
+		if !strings.HasPrefix(name, prefix) || i < len(prefix) {
+			continue
 		}
-
-		// 获取ConnectionTimeoutRate统计
-		timeoutResult, err := mc.getMBeanFullData(fmt.Sprintf("puppetlabs.puppetdb.database:name=%s.pool.ConnectionTimeoutRate", pool))
-		if err == nil && timeoutResult != nil {
-			// 提取速率统计信息
-			if val, ok := timeoutResult["OneMinuteRate"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionTimeoutRateOneMinute"] = floatVal
-				}
-			}
-			if val, ok := timeoutResult["FiveMinuteRate"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionTimeoutRateFiveMinute"] = floatVal
-				}
-			}
-			if val, ok := timeoutResult["FifteenMinuteRate"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionTimeoutRateFifteenMinute"] = floatVal
-				}
-			}
-			if val, ok := timeoutResult["MeanRate"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionTimeoutRateMean"] = floatVal
-				}
-			}
-			if val, ok := timeoutResult["Count"]; ok {
-				if floatVal, err := mc.parseMBeanValue(val, pool); err == nil {
-					metrics[pool]["ConnectionTimeoutRateCount"] = floatVal
-				}
-			}
+		command, metric := name[len(prefix):i], name[i+1:]
+		value, ok := s.mc.mbeanValue(attributes)
+		if command == "" || !ok {
+			continue
+		}
+		if metrics[command] == nil {
+			metrics[command] = make(map[string]float64)
 		}
+		metrics[command][metric] = value
 	}
-
-	return metrics, nil
+	return metrics, err
 }
 
-// GetJVMMetrics 获取JVM指标
-func (mc *MetricsClient) GetJVMMetrics() (map[string]float64, error) {
-	metrics := make(map[string]float64)
-
-	// 内存指标
-	memoryTypes := []string{"HeapMemoryUsage", "NonHeapMemoryUsage"}
-	for _, memoryType := range memoryTypes {
-		value, err := mc.getMBeanValue(fmt.Sprintf("java.lang:type=Memory.%s.used", memoryType))
-		if err == nil {
-			metrics[fmt.Sprintf("memory_%s_used", memoryType)] = value
-		}
-
-		maxValue, err := mc.getMBeanValue(fmt.Sprintf("java.lang:type=Memory.%s.max", memoryType))
-		if err == nil {
-			metrics[fmt.Sprintf("memory_%s_max", memoryType)] = maxValue
-		}
+// namedMBeans 返回指标组中 prefix 下的 MBean，以 name 属性为键的完整数据
+func (s *MetricsSnapshot) namedMBeans(group string, prefix string) (map[string]map[string]interface{}, error) {
+	data, err := s.result(group)
+	if data == nil {
+		return nil, err
 	}
 
-	// 线程指标
-	threadCount, err := mc.getMBeanValue("java.lang:type=Threading.ThreadCount")
-	if err == nil {
-		metrics["threads_active"] = threadCount
+	metrics := make(map[string]map[string]interface{})
+	for mbean, attributes := range data {
+		if strings.HasPrefix(mbean, prefix) {
+			metrics[strings.TrimPrefix(mbean, prefix)] = attributes
+		}
 	}
-
-	return metrics, nil
+	return metrics, err
 }
 
-// GetJVMDetailedMetrics 获取详细的JVM指标，包括内存池、垃圾收集器、运行时系统等
-func (mc *MetricsClient) GetJVMDetailedMetrics() (map[string]interface{}, error) {
-	metrics := make(map[string]interface{})
+// dbPoolGaugeMBeans 连接池的 HikariCP 数值 MBean 后缀
+var dbPoolGaugeMBeans = []string{"ActiveConnections", "IdleConnections", "TotalConnections", "PendingConnections", "MaxConnections", "MinConnections"}
+
+// dbPoolStatsMBeans 连接池的 Dropwizard 统计 MBean 后缀
+var dbPoolStatsMBeans = []string{"Usage", "Wait", "ConnectionCreation", "ConnectionTimeoutRate"}
 
-	// 内存池指标
-	memoryPools := []string{
-		"java.lang:name=G1 Eden Space,type=MemoryPool",
-		"java.lang:name=G1 Old Gen,type=MemoryPool",
-		"java.lang:name=G1 Survivor Space,type=MemoryPool",
-		"java.lang:name=Metaspace,type=MemoryPool",
+// databaseMBeans 返回以通配符读取的连接池 MBean，按连接池和 MBean 后缀分组
+func (s *MetricsSnapshot) databaseMBeans() (map[string]map[string]map[string]interface{}, error) {
+	data, err := s.result("database")
+	if data == nil {
+		return nil, err
 	}
 
-	for _, pool := range memoryPools {
-		poolData, err := mc.getMBeanFullData(pool)
-		if err == nil && poolData != nil {
-			poolName := extractPoolName(pool)
-			metrics[fmt.Sprintf("memory_pool_%s", poolName)] = poolData
+	pools := make(map[string]map[string]map[string]interface{})
+	for mbean, attributes := range data {
+		pool, metric, ok := parseDatabaseMBean(mbean)
+		if !ok {
+			continue
 		}
+		if pools[pool] == nil {
+			pools[pool] = make(map[string]map[string]interface{})
+		}
+		pools[pool][metric] = attributes
 	}
+	return pools, err
+}
 
-	// 垃圾收集器指标
-	garbageCollectors := []string{
-		"java.lang:name=G1 Young Generation,type=GarbageCollector",
-		"java.lang:name=G1 Old Generation,type=GarbageCollector",
+// parseDatabaseMBean 解析形如 "puppetlabs.puppetdb.database:name=PDBReadPool.pool.Usage" 的 MBean 名称
+func parseDatabaseMBean(mbean string) (pool string, metric string, ok bool) {
+	if !strings.HasPrefix(mbean, databasePrefix) {
+		return
 	}
-
-	for _, gc := range garbageCollectors {
-		gcData, err := mc.getMBeanFullData(gc)
-		if err == nil && gcData != nil {
-			gcName := extractGCName(gc)
-			metrics[fmt.Sprintf("gc_%s", gcName)] = gcData
-		}
+	parts := strings.SplitN(strings.TrimPrefix(mbean, databasePrefix), ".pool.", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return
 	}
+	return parts[0], parts[1], true
+}
 
-	// 运行时系统指标
-	systemMBeans := []string{
-		"java.lang:type=ClassLoading",
-		"java.lang:type=Compilation",
-		"java.lang:type=OperatingSystem",
-		"java.lang:type=Runtime",
-		"java.lang:type=Threading",
+// GetDBMetrics 获取数据库连接池指标
+func (s *MetricsSnapshot) GetDBMetrics() (map[string]map[string]float64, error) {
+	pools, err := s.databaseMBeans()
+	if pools == nil {
+		return nil, err
 	}
 
-	for _, mbean := range systemMBeans {
-		mbeanData, err := mc.getMBeanFullData(mbean)
-		if err == nil && mbeanData != nil {
-			mbeanName := extractMBeanName(mbean)
-			metrics[mbeanName] = mbeanData
+	metrics := make(map[string]map[string]float64)
+	for pool, data := range pools {
+		metrics[pool] = make(map[string]float64)
+		for _, metric := range dbPoolGaugeMBeans {
+			if value, ok := s.mc.mbeanValue(data[metric]); ok {
+				metrics[pool][metric] = value
+			}
 		}
 	}
 
-	return metrics, nil
+	return metrics, err
 }
 
-// GetJVMStandardMetrics 获取标准化的JVM指标，便于Prometheus导出
-func (mc *MetricsClient) GetJVMStandardMetrics() (map[string]float64, error) {
-	metrics := make(map[string]float64)
-
-	// 内存池使用量
-	memoryPoolMetrics := []struct {
-		mbean      string
-		metricName string
-	}{
-		{"java.lang:name=G1 Eden Space,type=MemoryPool", "memory_pool_g1_eden_used"},
-		{"java.lang:name=G1 Old Gen,type=MemoryPool", "memory_pool_g1_old_gen_used"},
-		{"java.lang:name=G1 Survivor Space,type=MemoryPool", "memory_pool_g1_survivor_used"},
-		{"java.lang:name=Metaspace,type=MemoryPool", "memory_pool_metaspace_used"},
+// GetDBPoolStats 获取数据库连接池的 Dropwizard 统计数据（使用、等待、连接创建和超时），按连接池和 MBean 后缀分组
+func (s *MetricsSnapshot) GetDBPoolStats() (map[string]map[string]map[string]interface{}, error) {
+	pools, err := s.databaseMBeans()
+	if pools == nil {
+		return nil, err
 	}
 
-	for _, mp := range memoryPoolMetrics {
-		fullData, err := mc.getMBeanFullData(mp.mbean)
-		if err == nil && fullData != nil {
-			if usage, ok := fullData["Usage"].(map[string]interface{}); ok {
-				if used, ok := usage["used"].(float64); ok {
-					metrics[mp.metricName] = used
-				}
+	metrics := make(map[string]map[string]map[string]interface{})
+	for pool, data := range pools {
+		metrics[pool] = make(map[string]map[string]interface{})
+		for _, name := range dbPoolStatsMBeans {
+			if attributes, ok := data[name]; ok {
+				metrics[pool][name] = attributes
 			}
 		}
 	}
 
-	// 垃圾收集器统计
-	gcMetrics := []struct {
-		mbean      string
-		metricName string
-		field      string
-	}{
-		{"java.lang:name=G1 Young Generation,type=GarbageCollector", "gc_g1_young_collection_count", "CollectionCount"},
-		{"java.lang:name=G1 Young Generation,type=GarbageCollector", "gc_g1_young_collection_time", "CollectionTime"},
-		{"java.lang:name=G1 Old Generation,type=GarbageCollector", "gc_g1_old_collection_count", "CollectionCount"},
-		{"java.lang:name=G1 Old Generation,type=GarbageCollector", "gc_g1_old_collection_time", "CollectionTime"},
-	}
+	return metrics, err
+}
 
-	for _, gc := range gcMetrics {
-		value, err := mc.getMBeanValueFromField(gc.mbean, gc.field)
-		if err == nil {
-			metrics[gc.metricName] = value
-		}
-	}
+// jvmReadRequests JVM 内存和线程数的读取请求
+var jvmReadRequests = []ReadRequest{
+	NewReadRequest("java.lang:type=Memory", "HeapMemoryUsage", "NonHeapMemoryUsage"),
+	NewReadRequest("java.lang:type=Threading", "ThreadCount"),
+}
 
-	// 类加载统计
-	classLoadingMetrics := []struct {
-		field      string
-		metricName string
-	}{
-		{"LoadedClassCount", "class_loading_loaded_count"},
-		{"UnloadedClassCount", "class_loading_unloaded_count"},
-		{"TotalLoadedClassCount", "class_loading_total_loaded_class_count"},
+// GetJVMMetrics 获取JVM指标
+func (s *MetricsSnapshot) GetJVMMetrics() (map[string]float64, error) {
+	data, err := s.result("jvm")
+	if data == nil {
+		return nil, err
 	}
 
-	for _, cl := range classLoadingMetrics {
-		value, err := mc.getMBeanValueFromField("java.lang:type=ClassLoading", cl.field)
-		if err == nil {
-			metrics[cl.metricName] = value
+	// 内存指标
+	metrics := make(map[string]float64)
+	for _, memoryType := range []string{"HeapMemoryUsage", "NonHeapMemoryUsage"} {
+		if value, ok := s.mc.mbeanField(data["java.lang:type=Memory"], memoryType+".used"); ok {
+			metrics[fmt.Sprintf("memory_%s_used", memoryType)] = value
+		}
+		if value, ok := s.mc.mbeanField(data["java.lang:type=Memory"], memoryType+".max"); ok {
+			metrics[fmt.Sprintf("memory_%s_max", memoryType)] = value
 		}
 	}
 
-	// 编译统计
-	compilationTime, err := mc.getMBeanValueFromField("java.lang:type=Compilation", "TotalCompilationTime")
-	if err == nil {
-		metrics["compilation_total_time"] = compilationTime
+	// 线程指标
+	if value, ok := s.mc.mbeanField(data["java.lang:type=Threading"], "ThreadCount"); ok {
+		metrics["threads_active"] = value
 	}
 
-	// 操作系统指标
-	osMetrics := []struct {
-		field      string
-		metricName string
-	}{
-		{"OpenFileDescriptorCount", "os_open_file_descriptors"},
-		{"CommittedVirtualMemorySize", "os_committed_virtual_memory"},
-		{"FreePhysicalMemorySize", "os_free_physical_memory"},
-		{"SystemLoadAverage", "os_system_load_average"},
-		{"ProcessCpuLoad", "os_process_cpu_load"},
-		{"FreeSwapSpaceSize", "os_free_swap_space"},
-		{"TotalPhysicalMemorySize", "os_total_physical_memory"},
-		{"TotalSwapSpaceSize", "os_total_swap_space"},
-		{"ProcessCpuTime", "os_process_cpu_time"},
-		{"MaxFileDescriptorCount", "os_max_file_descriptors"},
-		{"SystemCpuLoad", "os_system_cpu_load"},
-		{"AvailableProcessors", "os_available_processors"},
-		{"CpuLoad", "os_cpu_load"},
-		{"FreeMemorySize", "os_free_memory"},
-	}
+	return metrics, err
+}
 
-	for _, os := range osMetrics {
-		value, err := mc.getMBeanValueFromField("java.lang:type=OperatingSystem", os.field)
-		if err == nil {
-			metrics[os.metricName] = value
-		}
-	}
+// jvmAttribute 单个 JVM 属性到指标键的映射，scale 将原始单位换算为指标单位
+type jvmAttribute struct {
+	field string
+	key   string
+	scale float64
+}
 
-	// 运行时指标
-	runtimeMetrics := []struct {
-		field      string
-		metricName string
-	}{
-		{"Uptime", "runtime_uptime"},
-		{"StartTime", "runtime_start_time"},
-	}
+// jvmAttributes 按 MBean 分组的 JVM 运行时属性
+var jvmAttributes = map[string][]jvmAttribute{
+	"java.lang:type=ClassLoading": {
+		{"LoadedClassCount", "jvm_class_loading_loaded_class_count", 1},
+		{"UnloadedClassCount", "jvm_class_loading_unloaded_class_count", 1},
+		{"TotalLoadedClassCount", "jvm_class_loading_total_loaded_class_count", 1},
+	},
+	"java.lang:type=Compilation": {
+		{"TotalCompilationTime", "jvm_compilation_total_time_seconds", 1e-3},
+	},
+	"java.lang:type=OperatingSystem": {
+		{"OpenFileDescriptorCount", "jvm_operating_system_open_file_descriptors", 1},
+		{"CommittedVirtualMemorySize", "jvm_operating_system_committed_virtual_memory_bytes", 1},
+		{"FreePhysicalMemorySize", "jvm_operating_system_free_physical_memory_bytes", 1},
+		{"SystemLoadAverage", "jvm_operating_system_system_load_average", 1},
+		{"ProcessCpuLoad", "jvm_operating_system_process_cpu_load", 1},
+		{"FreeSwapSpaceSize", "jvm_operating_system_free_swap_space_bytes", 1},
+		{"TotalPhysicalMemorySize", "jvm_operating_system_total_physical_memory_bytes", 1},
+		{"TotalSwapSpaceSize", "jvm_operating_system_total_swap_space_bytes", 1},
+		{"ProcessCpuTime", "jvm_operating_system_process_cpu_time_seconds", 1e-9},
+		{"MaxFileDescriptorCount", "jvm_operating_system_max_file_descriptors", 1},
+		{"SystemCpuLoad", "jvm_operating_system_system_cpu_load", 1},
+		{"AvailableProcessors", "jvm_operating_system_available_processors", 1},
+		{"CpuLoad", "jvm_operating_system_cpu_load", 1},
+		{"FreeMemorySize", "jvm_operating_system_free_memory_bytes", 1},
+	},
+	"java.lang:type=Runtime": {
+		{"Uptime", "jvm_runtime_uptime_seconds", 1e-3},
+		{"StartTime", "jvm_runtime_start_time_seconds", 1e-3},
+	},
+	"java.lang:type=Threading": {
+		{"TotalStartedThreadCount", "jvm_threading_total_started_threads", 1},
+		{"PeakThreadCount", "jvm_threading_peak_thread_count", 1},
+		{"DaemonThreadCount", "jvm_threading_daemon_thread_count", 1},
+		{"CurrentThreadAllocatedBytes", "jvm_threading_current_thread_allocated_bytes", 1},
+		{"ThreadAllocatedMemoryEnabled", "jvm_threading_allocated_memory_enabled", 1},
+		{"ThreadCpuTimeEnabled", "jvm_threading_cpu_time_enabled", 1},
+	},
+}
 
-	for _, rt := range runtimeMetrics {
-		value, err := mc.getMBeanValueFromField("java.lang:type=Runtime", rt.field)
-		if err == nil {
-			metrics[rt.metricName] = value
+// jvmAttributeReadRequests jvmAttributes 中各 MBean 的读取请求
+func jvmAttributeReadRequests() []ReadRequest {
+	var requests []ReadRequest
+	for mbean, attributes := range jvmAttributes {
+		fields := make([]string, len(attributes))
+		for i, attribute := range attributes {
+			fields[i] = attribute.field
 		}
+		requests = append(requests, NewReadRequest(mbean, fields...))
 	}
+	return requests
+}
 
-	// 线程指标
-	threadingMetrics := []struct {
-		field      string
-		metricName string
-	}{
-		{"TotalStartedThreadCount", "threading_total_started_threads"},
-		{"PeakThreadCount", "threading_peak_thread_count"},
-		{"DaemonThreadCount", "threading_daemon_thread_count"},
-		{"CurrentThreadAllocatedBytes", "threading_current_thread_allocated_bytes"},
-		{"ThreadAllocatedMemoryEnabled", "threading_allocated_memory_enabled"},
-		{"ThreadCpuTimeEnabled", "threading_cpu_time_enabled"},
+// GetJVMComprehensiveMetrics 获取类加载、编译、操作系统、运行时和线程等 JVM 指标
+func (s *MetricsSnapshot) GetJVMComprehensiveMetrics() (map[string]float64, error) {
+	data, err := s.result("jvm_comprehensive")
+	if data == nil {
+		return nil, err
 	}
 
-	for _, th := range threadingMetrics {
-		value, err := mc.getMBeanValueFromField("java.lang:type=Threading", th.field)
-		if err == nil {
-			metrics[th.metricName] = value
+	metrics := make(map[string]float64)
+	for mbean, attributes := range jvmAttributes {
+		for _, attribute := range attributes {
+			if value, ok := s.mc.mbeanField(data[mbean], attribute.field); ok {
+				metrics[attribute.key] = value * attribute.scale
+			}
 		}
 	}
 
-	return metrics, nil
+	return metrics, err
 }
 
-// GetHTTPMetrics 获取HTTP服务指标
-func (mc *MetricsClient) GetHTTPMetrics() (map[string]map[string]float64, error) {
-	metrics := make(map[string]map[string]float64)
-
-	// 主要HTTP端点
-	endpoints := []string{"/pdb/query/v4/nodes", "/pdb/query/v4/resources", "/pdb/query/v4/reports", "/metrics/v2/read", "/metrics/v2"}
-
-	for _, endpoint := range endpoints {
-		metrics[endpoint] = make(map[string]float64)
-
-		// 获取服务时间统计
-		serviceTimeData, err := mc.getMBeanFullData(fmt.Sprintf("puppetlabs.puppetdb.http:name=%s.service-time", endpoint))
-		if err == nil && serviceTimeData != nil {
-			// 分位数统计
-			if val, ok := serviceTimeData["50thPercentile"].(float64); ok {
-				metrics[endpoint]["service_time_50th_percentile"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["75thPercentile"].(float64); ok {
-				metrics[endpoint]["service_time_75th_percentile"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["95thPercentile"].(float64); ok {
-				metrics[endpoint]["service_time_95th_percentile"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["98thPercentile"].(float64); ok {
-				metrics[endpoint]["service_time_98th_percentile"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["99thPercentile"].(float64); ok {
-				metrics[endpoint]["service_time_99th_percentile"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["999thPercentile"].(float64); ok {
-				metrics[endpoint]["service_time_999th_percentile"] = val / 1000.0 // 转换为秒
-			}
-
-			// 基础统计
-			if val, ok := serviceTimeData["Mean"].(float64); ok {
-				metrics[endpoint]["service_time_mean"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["StdDev"].(float64); ok {
-				metrics[endpoint]["service_time_stddev"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["Min"].(float64); ok {
-				metrics[endpoint]["service_time_min"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["Max"].(float64); ok {
-				metrics[endpoint]["service_time_max"] = val / 1000.0 // 转换为秒
-			}
-			if val, ok := serviceTimeData["Count"].(float64); ok {
-				metrics[endpoint]["service_time_count"] = val
-			}
-		}
-
-		// 获取请求计数统计（200状态码）
-		requestCountData, err := mc.getMBeanFullData(fmt.Sprintf("puppetlabs.puppetdb.http:name=%s.200", endpoint))
-		if err == nil && requestCountData != nil {
-			// 请求速率统计
-			if val, ok := requestCountData["OneMinuteRate"].(float64); ok {
-				metrics[endpoint]["requests_200_one_minute_rate"] = val
-			}
-			if val, ok := requestCountData["FiveMinuteRate"].(float64); ok {
-				metrics[endpoint]["requests_200_five_minute_rate"] = val
-			}
-			if val, ok := requestCountData["FifteenMinuteRate"].(float64); ok {
-				metrics[endpoint]["requests_200_fifteen_minute_rate"] = val
-			}
-			if val, ok := requestCountData["MeanRate"].(float64); ok {
-				metrics[endpoint]["requests_200_mean_rate"] = val
-			}
-			if val, ok := requestCountData["Count"].(float64); ok {
-				metrics[endpoint]["requests_200_count"] = val
-			}
-		}
-	}
+// JVMMemoryPool 单个内存池（包括代码缓存堆）的使用情况，未提供的值为 -1
+type JVMMemoryPool struct {
+	Name      string
+	Used      float64
+	Committed float64
+	Max       float64
+	PeakUsed  float64
+}
 
-	return metrics, nil
+// JVMGarbageCollector 单个垃圾收集器的累计统计，CollectionTime 单位为秒
+type JVMGarbageCollector struct {
+	Name            string
+	CollectionCount float64
+	CollectionTime  float64
 }
 
-// getMBeanValue 获取单个MBean指标值
-func (mc *MetricsClient) getMBeanValue(mbeanName string) (float64, error) {
-	// 使用 /metrics/v2/read 接口替代 /metrics/v1/mbeans
-	endpoint := "/metrics/v2/read"
+// JVMBufferPool 单个 NIO 缓冲池（direct、mapped）的使用情况，未提供的值为 -1
+type JVMBufferPool struct {
+	Name          string
+	Count         float64
+	MemoryUsed    float64
+	TotalCapacity float64
+}
 
-	// 准备请求体，使用新的格式
-	requestBody, err := json.Marshal(map[string]interface{}{
-		"mbean": mbeanName,
-	})
-	if err != nil {
-		return 0, err
-	}
+// JVMPools 从 JVM 中发现的内存池、垃圾收集器和缓冲池
+type JVMPools struct {
+	MemoryPools       []JVMMemoryPool
+	GarbageCollectors []JVMGarbageCollector
+	BufferPools       []JVMBufferPool
+}
 
-	resp, err := mc.Post(endpoint, "application/json", requestBody)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
+// jvmPoolReadRequests 以通配符读取内存池、垃圾收集器和缓冲池的请求
+var jvmPoolReadRequests = []ReadRequest{
+	NewReadRequest("java.lang:type=MemoryPool,*", "Usage", "PeakUsage"),
+	NewReadRequest("java.lang:type=GarbageCollector,*", "CollectionCount", "CollectionTime"),
+	NewReadRequest("java.nio:type=BufferPool,*", "Count", "MemoryUsed", "TotalCapacity"),
+}
 
-	if resp.StatusCode != 200 {
-		return 0, fmt.Errorf("failed to get mbean %s: status %d", mbeanName, resp.StatusCode)
+// GetJVMPools 以通配符读取 JVM 当前的全部内存池、垃圾收集器和缓冲池
+// 名称来自 MBean 的 name 属性，因此适用于 G1、ZGC、Shenandoah、Parallel 等任意收集器
+func (s *MetricsSnapshot) GetJVMPools() (*JVMPools, error) {
+	data, err := s.result("jvm_pools")
+	if data == nil {
+		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, err
-	}
+	field := func(attributes map[string]interface{}, name string) float64 {
+		if value, ok := s.mc.mbeanField(attributes, name); ok {
+			return value
+		}
+		return -1
+	}
+
+	pools := &JVMPools{}
+	for mbean, attributes := range data {
+		name := jvmPoolName(mbean)
+		if name == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(mbean, "java.lang:") && strings.Contains(mbean, "type=MemoryPool"):
+			pools.MemoryPools = append(pools.MemoryPools, JVMMemoryPool{
+				Name:      name,
+				Used:      field(attributes, "Usage.used"),
+				Committed: field(attributes, "Usage.committed"),
+				Max:       field(attributes, "Usage.max"),
+				PeakUsed:  field(attributes, "PeakUsage.used"),
+			})
+		case strings.HasPrefix(mbean, "java.lang:") && strings.Contains(mbean, "type=GarbageCollector"):
+			collectionTime := field(attributes, "CollectionTime")
+			if collectionTime >= 0 {
+				collectionTime /= 1000
+			}
+			pools.GarbageCollectors = append(pools.GarbageCollectors, JVMGarbageCollector{
+				Name:            name,
+				CollectionCount: field(attributes, "CollectionCount"),
+				CollectionTime:  collectionTime,
+			})
+		case strings.HasPrefix(mbean, "java.nio:") && strings.Contains(mbean, "type=BufferPool"):
+			pools.BufferPools = append(pools.BufferPools, JVMBufferPool{
+				Name:          name,
+				Count:         field(attributes, "Count"),
+				MemoryUsed:    field(attributes, "MemoryUsed"),
+				TotalCapacity: field(attributes, "TotalCapacity"),
+			})
+		}
+	}
+
+	return pools, err
+}
 
-	// 根据不同的MBean类型提取数值
-	return mc.extractValueFromMBean(result, mbeanName)
+// JVMThreadStates JVM 线程状态统计
+type JVMThreadStates struct {
+	// States 按 Thread.State（RUNNABLE、BLOCKED、WAITING 等）统计的线程数
+	States map[string]float64
+	// Deadlocked 处于死锁的线程数
+	Deadlocked float64
+	// DeadlockedNames 处于死锁的线程名称
+	DeadlockedNames []string
 }
 
-// getMBeanFullData 获取MBean的完整数据（不提取具体值）
-func (mc *MetricsClient) getMBeanFullData(mbeanName string) (map[string]interface{}, error) {
-	// 使用 /metrics/v2/read 接口替代 /metrics/v1/mbeans
-	endpoint := "/metrics/v2/read"
+// GetJVMThreadStates 通过 Jolokia exec 调用 findDeadlockedThreads 和 getThreadInfo 统计线程状态
+// 需要 PuppetDB 的 Jolokia 访问策略允许对 java.lang:type=Threading 执行操作
+func (mc *MetricsClient) GetJVMThreadStates() (*JVMThreadStates, error) {
+	const threading = "java.lang:type=Threading"
 
-	// 准备请求体，使用新的格式
-	requestBody, err := json.Marshal(map[string]interface{}{
-		"mbean": mbeanName,
-	})
+	data, err := mc.Read(NewReadRequest(threading, "AllThreadIds"))
 	if err != nil {
 		return nil, err
 	}
+	ids, _ := data[threading]["AllThreadIds"].([]interface{})
 
-	resp, err := mc.Post(endpoint, "application/json", requestBody)
+	deadlocked, err := mc.Exec(threading, "findDeadlockedThreads")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get mbean %s: status %d", mbeanName, resp.StatusCode)
+	deadlockedIDs := make(map[float64]bool)
+	if list, ok := deadlocked.([]interface{}); ok {
+		for _, id := range list {
+			if v, ok := id.(float64); ok {
+				deadlockedIDs[v] = true
+			}
+		}
 	}
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	// 不带堆栈的 getThreadInfo 只返回线程名称和状态，开销远小于 dumpAllThreads
+	infos, err := mc.Exec(threading, "getThreadInfo([J)", ids)
+	if err != nil {
 		return nil, err
 	}
 
-	// 返回value字段中的数据
-	if val, ok := result["value"]; ok {
-		if valueMap, ok := val.(map[string]interface{}); ok {
-			return valueMap, nil
+	states := &JVMThreadStates{States: make(map[string]float64), Deadlocked: float64(len(deadlockedIDs))}
+	list, _ := infos.([]interface{})
+	for _, info := range list {
+		// 获取信息前已结束的线程返回 null
+		thread, ok := info.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if state, ok := thread["threadState"].(string); ok {
+			states.States[state]++
+		}
+		if id, ok := thread["threadId"].(float64); ok && deadlockedIDs[id] {
+			name, _ := thread["threadName"].(string)
+			states.DeadlockedNames = append(states.DeadlockedNames, name)
 		}
 	}
 
-	return nil, fmt.Errorf("no value data found in MBean result for %s", mbeanName)
+	return states, nil
 }
 
-// GetMetricsBulk 批量获取多个MBean指标
-func (mc *MetricsClient) GetMetricsBulk(mbeanNames []string) (map[string]float64, error) {
-	endpoint := "/metrics/v2/read"
-
-	// 准备请求体，使用新的格式
-	request := make([]map[string]interface{}, len(mbeanNames))
-	for i, mbeanName := range mbeanNames {
-		request[i] = map[string]interface{}{
-			"mbean": mbeanName,
-		}
-	}
-
-	requestBody, err := json.Marshal(request)
-	if err != nil {
+// GetHTTPMetrics 以通配符读取全部 HTTP 端点 MBean，返回各端点 service-time 计时器和各状态码计量器的 Dropwizard 数据
+// 端点按 templateHTTPEndpoint 模板化，同一模板的多个端点（例如不同报告哈希）合并为一个
+func (s *MetricsSnapshot) GetHTTPMetrics() (map[string]map[string]map[string]interface{}, error) {
+	data, err := s.result("http")
+	if data == nil {
 		return nil, err
 	}
 
-	resp, err := mc.Post(endpoint, "application/json", requestBody)
-	if err != nil {
-		return nil, err
+	metrics := make(map[string]map[string]map[string]interface{})
+	for mbean, attributes := range data {
+		endpoint, name, ok := parseHTTPMBean(strings.TrimPrefix(mbean, httpPrefix))
+		if !ok || !strings.HasPrefix(mbean, httpPrefix) {
+			continue
+		}
+		endpoint = templateHTTPEndpoint(endpoint)
+		if metrics[endpoint] == nil {
+			metrics[endpoint] = make(map[string]map[string]interface{})
+		}
+		metrics[endpoint][name] = mergeDropwizard(metrics[endpoint][name], attributes)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get bulk metrics: status %d", resp.StatusCode)
-	}
+	return metrics, err
+}
 
-	var results []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return nil, err
+// parseHTTPMBean 将 "/pdb/query/v4/nodes.service-time" 形式的 MBean 名称拆分为端点和后缀（service-time 或状态码）
+func parseHTTPMBean(name string) (endpoint string, suffix string, ok bool) {
+	i := strings.LastIndex(name, ".")
+	if i <= 0 {
+		return
 	}
-
-	// 解析结果
-	metrics := make(map[string]float64)
-	for i, result := range results {
-		if i < len(mbeanNames) {
-			value, err := mc.extractValueFromMBean(result, mbeanNames[i])
-			if err == nil {
-				metrics[mbeanNames[i]] = value
-			}
+	endpoint, suffix = name[:i], name[i+1:]
+	if suffix != "service-time" {
+		if code, err := strconv.Atoi(suffix); err != nil || code < 100 || code > 599 {
+			return
 		}
 	}
+	return endpoint, suffix, true
+}
 
-	return metrics, nil
+// httpPathParams 查询 API 中后面跟随路径参数的集合，以及这些参数的模板名称
+var httpPathParams = map[string][]string{
+	"nodes":        {"{certname}"},
+	"catalogs":     {"{certname}"},
+	"factsets":     {"{certname}"},
+	"producers":    {"{producer}"},
+	"environments": {"{environment}"},
+	"reports":      {"{hash}"},
+	"facts":        {"{fact}", "{value}"},
+	"resources":    {"{type}", "{title}"},
 }
 
-// extractValueFromMBean 从MBean结果中提取数值
-func (mc *MetricsClient) extractValueFromMBean(result map[string]interface{}, mbeanName string) (float64, error) {
-	// 根据MBean类型提取相应的值
-	// 首先尝试提取 value 字段中的 Value 子字段（新的 /metrics/v2/read 格式）
-	if val, ok := result["value"]; ok {
-		if valueMap, ok := val.(map[string]interface{}); ok {
-			// 检查 value.Value 字段
-			if innerVal, ok := valueMap["Value"]; ok {
-				return mc.parseMBeanValue(innerVal, mbeanName)
-			}
-			// 检查其他可能的字段
-			if innerVal, ok := valueMap["value"]; ok {
-				return mc.parseMBeanValue(innerVal, mbeanName)
+// templateHTTPEndpoint 将端点中的路径参数替换为模板，避免按节点和报告哈希产生大量标签值
+// 例如 "/pdb/query/v4/nodes/web1/facts" 为 "/pdb/query/v4/nodes/{certname}/facts"，
+// 其他位置的十六进制哈希（至少 32 位）替换为 {hash}
+func templateHTTPEndpoint(endpoint string) string {
+	segments := strings.Split(endpoint, "/")
+	for i := 0; i < len(segments); i++ {
+		if params, ok := httpPathParams[segments[i]]; ok {
+			for _, param := range params {
+				if i+1 >= len(segments) || httpPathParams[segments[i+1]] != nil {
+					break
+				}
+				i++
+				segments[i] = param
 			}
-		} else {
-			// 如果 value 不是 map，直接解析
-			return mc.parseMBeanValue(val, mbeanName)
+			continue
+		}
+		if isHexHash(segments[i]) {
+			segments[i] = "{hash}"
 		}
 	}
+	return strings.Join(segments, "/")
+}
 
-	// 兼容旧格式：直接提取 Value 字段
-	if val, ok := result["Value"]; ok {
-		return mc.parseMBeanValue(val, mbeanName)
+// isHexHash 判断路径段是否为十六进制哈希
+func isHexHash(segment string) bool {
+	if len(segment) < 32 {
+		return false
 	}
-
-	// 对于某些指标，可能需要提取特定的字段
-	if val, ok := result["Count"]; ok {
-		return mc.parseMBeanValue(val, mbeanName)
+	for _, r := range segment {
+		if !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'f') {
+			return false
+		}
 	}
+	return true
+}
 
-	if val, ok := result["Mean"]; ok {
-		return mc.parseMBeanValue(val, mbeanName)
-	}
+// mergeDropwizard 合并同一模板下多个端点的 Dropwizard 数据
+// 分位数、Min、Max 和 StdDev 无法由各端点的值合并，合并后只保留计数和速率（相加）、
+// 按计数加权的平均值（使 Mean * Count 为准确的总和）以及单位等非数值属性；只有一个端点的模板保留完整数据
+func mergeDropwizard(total map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+	if total == nil {
+		return data
+	}
+
+	totalCount, _ := total["Count"].(float64)
+	count, _ := data["Count"].(float64)
+	merged := make(map[string]interface{}, len(total))
+	for _, source := range []map[string]interface{}{total, data} {
+		for field, value := range source {
+			if _, done := merged[field]; done {
+				continue
+			}
+			a, aok := total[field].(float64)
+			b, bok := data[field].(float64)
+			switch {
+			case !aok && !bok:
+				merged[field] = value
+			case field == "Count" || strings.HasSuffix(field, "Rate"):
+				merged[field] = a + b
+			case field == "Mean" && totalCount+count > 0:
+				merged[field] = (a*totalCount + b*count) / (totalCount + count)
+			case field == "Mean":
+				merged[field] = float64(0)
+			}
+		}
+	}
+	return merged
+}
 
-	// 对于某些MBean，可能需要检查特定的属性
-	// 例如，内存相关的MBean可能有 used, max 等字段
-	if strings.Contains(mbeanName, "Memory") {
-		if val, ok := result["used"]; ok {
-			return mc.parseMBeanValue(val, mbeanName)
-		}
-		if val, ok := result["max"]; ok {
-			return mc.parseMBeanValue(val, mbeanName)
+// mbeanValue 读取 MBean 的 Value 属性，没有时使用 Count（计量器和计时器）
+func (mc *MetricsClient) mbeanValue(data map[string]interface{}) (float64, bool) {
+	for _, field := range []string{"Value", "Count"} {
+		if value, ok := mc.mbeanField(data, field); ok {
+			return value, true
 		}
 	}
+	return 0, false
+}
 
-	// 对于线程相关的MBean
-	if strings.Contains(mbeanName, "Threading") {
-		if val, ok := result["ThreadCount"]; ok {
-			return mc.parseMBeanValue(val, mbeanName)
+// mbeanField 读取 MBean 的数值属性，支持用 "." 访问复合属性（例如 HeapMemoryUsage.used）
+func (mc *MetricsClient) mbeanField(data map[string]interface{}, field string) (float64, bool) {
+	var current interface{} = data
+	for _, part := range strings.Split(field, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		if current, ok = m[part]; !ok {
+			return 0, false
 		}
 	}
 
-	// 对于HTTP相关的MBean，检查 mean 或 count 字段
-	if strings.Contains(mbeanName, "http") {
-		if val, ok := result["mean"]; ok {
-			return mc.parseMBeanValue(val, mbeanName)
-		}
-		if val, ok := result["count"]; ok {
-			return mc.parseMBeanValue(val, mbeanName)
+	switch v := current.(type) {
+	case bool:
+		if v {
+			return 1, true
 		}
+		return 0, true
+	case nil:
+		return 0, false
+	default:
+		value, err := mc.parseMBeanValue(v, field)
+		return value, err == nil
 	}
-
-	return 0, fmt.Errorf("no suitable value found in MBean result for %s", mbeanName)
 }
 
 // parseMBeanValue 解析MBean值，支持多种格式
@@ -801,247 +771,9 @@ func (mc *MetricsClient) parseStringValue(strVal string, mbeanName string) (floa
 	return 0, fmt.Errorf("cannot parse string value '%s' for MBean %s", strVal, mbeanName)
 }
 
-// GetAvailableMBeans 获取所有可用的MBean列表
-func (mc *MetricsClient) GetAvailableMBeans() ([]string, error) {
-	// 使用 /metrics/v2/list 接口替代 /metrics/v1/mbeans
-	var result map[string]interface{}
-	err := mc.get("/metrics/v2/list", "", &result)
-	if err != nil {
-		return nil, err
-	}
-
-	// 解析嵌套的JSON结构，提取MBean名称
-	var mbeans []string
-
-	// 检查value字段是否存在
-	if value, ok := result["value"]; ok {
-		if valueMap, ok := value.(map[string]interface{}); ok {
-			// 遍历value中的每个MBean及其属性
-			for mbeanName, attributes := range valueMap {
-				if attrsMap, ok := attributes.(map[string]interface{}); ok {
-					// 遍历所有属性，构建完整的MBean名称
-					for attrKey := range attrsMap {
-						if attrKey == "" {
-							// 空属性，只使用主名称
-							mbeans = append(mbeans, mbeanName)
-						} else {
-							// 非空属性，组合成完整的MBean名称
-							fullMBeanName := fmt.Sprintf("%s:%s", mbeanName, attrKey)
-							mbeans = append(mbeans, fullMBeanName)
-						}
-					}
-				} else {
-					// 如果属性不是map类型，只添加主MBean名称
-					mbeans = append(mbeans, mbeanName)
-				}
-			}
-		}
-	} else {
-		// 如果没有value字段，直接遍历顶层对象（兼容旧格式）
-		for mbeanName := range result {
-			// 跳过request字段，只处理MBean名称
-			if mbeanName != "request" {
-				mbeans = append(mbeans, mbeanName)
-			}
-		}
-	}
-
-	return mbeans, nil
-}
-
-// extractPoolName 从MBean名称中提取内存池名称
-func extractPoolName(mbeanName string) string {
-	// 从类似 "java.lang:name=G1 Eden Space,type=MemoryPool" 中提取 "g1_eden"
-	if strings.Contains(mbeanName, "name=") {
-		start := strings.Index(mbeanName, "name=") + 5
-		end := strings.Index(mbeanName[start:], ",")
-		if end == -1 {
-			end = len(mbeanName) - start
-		}
-		poolName := mbeanName[start : start+end]
-		// 清理名称，替换空格为下划线，转换为小写
-		poolName = strings.ReplaceAll(poolName, " ", "_")
-		poolName = strings.ToLower(poolName)
-		return poolName
-	}
-	return "unknown"
-}
-
-// extractGCName 从MBean名称中提取垃圾收集器名称
-func extractGCName(mbeanName string) string {
-	// 从类似 "java.lang:name=G1 Young Generation,type=GarbageCollector" 中提取 "g1_young"
-	if strings.Contains(mbeanName, "name=") {
-		start := strings.Index(mbeanName, "name=") + 5
-		end := strings.Index(mbeanName[start:], ",")
-		if end == -1 {
-			end = len(mbeanName) - start
-		}
-		gcName := mbeanName[start : start+end]
-		// 清理名称，替换空格为下划线，转换为小写
-		gcName = strings.ReplaceAll(gcName, " ", "_")
-		gcName = strings.ToLower(gcName)
-		return gcName
-	}
-	return "unknown"
-}
-
-// extractMBeanName 从MBean名称中提取简化的MBean名称
-func extractMBeanName(mbeanName string) string {
-	// 从类似 "java.lang:type=ClassLoading" 中提取 "classloading"
-	if strings.Contains(mbeanName, "type=") {
-		start := strings.Index(mbeanName, "type=") + 5
-		end := len(mbeanName)
-		mbeanName = mbeanName[start:end]
-	}
-	// 转换为小写
-	mbeanName = strings.ToLower(mbeanName)
-	return mbeanName
-}
-
-// getMBeanValueFromField 从MBean的特定字段中获取值
-func (mc *MetricsClient) getMBeanValueFromField(mbeanName string, field string) (float64, error) {
-	fullData, err := mc.getMBeanFullData(mbeanName)
-	if err != nil {
-		return 0, err
-	}
-
-	if fullData == nil {
-		return 0, fmt.Errorf("no data found for MBean %s", mbeanName)
-	}
-
-	// 尝试直接获取字段值
-	if val, ok := fullData[field]; ok {
-		return mc.parseMBeanValue(val, mbeanName)
-	}
-
-	// 对于某些MBean，字段可能在value子对象中
-	if val, ok := fullData["value"]; ok {
-		if valueMap, ok := val.(map[string]interface{}); ok {
-			if fieldVal, ok := valueMap[field]; ok {
-				return mc.parseMBeanValue(fieldVal, mbeanName)
-			}
-		}
-	}
-
-	return 0, fmt.Errorf("field %s not found in MBean %s", field, mbeanName)
-}
-
-// GetJVMComprehensiveMetrics 获取综合的JVM指标，包含所有重要的JMX指标
-func (mc *MetricsClient) GetJVMComprehensiveMetrics() (map[string]float64, error) {
-	// 使用批量获取提高效率
-	mbeanNames := []string{
-		// 内存池指标
-		"java.lang:name=G1 Eden Space,type=MemoryPool",
-		"java.lang:name=G1 Old Gen,type=MemoryPool",
-		"java.lang:name=G1 Survivor Space,type=MemoryPool",
-		"java.lang:name=Metaspace,type=MemoryPool",
-
-		// 垃圾收集器指标
-		"java.lang:name=G1 Young Generation,type=GarbageCollector",
-		"java.lang:name=G1 Old Generation,type=GarbageCollector",
-
-		// 运行时系统指标
-		"java.lang:type=ClassLoading",
-		"java.lang:type=Compilation",
-		"java.lang:type=OperatingSystem",
-		"java.lang:type=Runtime",
-		"java.lang:type=Threading",
-	}
-
-	// 批量获取指标值
-	bulkMetrics, err := mc.GetMetricsBulk(mbeanNames)
-	if err != nil {
-		// 如果批量获取失败，回退到逐个获取
-		return mc.GetJVMStandardMetrics()
-	}
-
-	// 处理批量获取的结果
-	metrics := make(map[string]float64)
-
-	for mbeanName, value := range bulkMetrics {
-		metricName := mc.convertMBeanToMetricName(mbeanName)
-		if metricName != "" {
-			metrics[metricName] = value
-		}
-	}
-
-	return metrics, nil
-}
-
-// convertMBeanToMetricName 将MBean名称转换为Prometheus指标名称
-func (mc *MetricsClient) convertMBeanToMetricName(mbeanName string) string {
-	// 内存池指标
-	if strings.Contains(mbeanName, "MemoryPool") {
-		poolName := extractPoolName(mbeanName)
-		return fmt.Sprintf("jvm_memory_pool_%s_used_bytes", poolName)
-	}
-
-	// 垃圾收集器指标
-	if strings.Contains(mbeanName, "GarbageCollector") {
-		gcName := extractGCName(mbeanName)
-		if strings.Contains(mbeanName, "CollectionCount") {
-			return fmt.Sprintf("jvm_gc_%s_collection_count", gcName)
-		}
-		if strings.Contains(mbeanName, "CollectionTime") {
-			return fmt.Sprintf("jvm_gc_%s_collection_time_seconds", gcName)
-		}
-	}
-
-	// 类加载指标
-	if strings.Contains(mbeanName, "ClassLoading") {
-		if strings.Contains(mbeanName, "LoadedClassCount") {
-			return "jvm_class_loading_loaded_class_count"
-		}
-		if strings.Contains(mbeanName, "UnloadedClassCount") {
-			return "jvm_class_loading_unloaded_class_count"
-		}
-		if strings.Contains(mbeanName, "TotalLoadedClassCount") {
-			return "jvm_class_loading_total_loaded_class_count"
-		}
-	}
-
-	// 编译指标
-	if strings.Contains(mbeanName, "Compilation") && strings.Contains(mbeanName, "TotalCompilationTime") {
-		return "jvm_compilation_total_time_seconds"
-	}
-
-	// 操作系统指标
-	if strings.Contains(mbeanName, "OperatingSystem") {
-		if strings.Contains(mbeanName, "OpenFileDescriptorCount") {
-			return "jvm_operating_system_open_file_descriptors"
-		}
-		if strings.Contains(mbeanName, "CommittedVirtualMemorySize") {
-			return "jvm_operating_system_committed_virtual_memory_bytes"
-		}
-		if strings.Contains(mbeanName, "FreePhysicalMemorySize") {
-			return "jvm_operating_system_free_physical_memory_bytes"
-		}
-		// 其他操作系统指标...
-	}
-
-	// 运行时指标
-	if strings.Contains(mbeanName, "Runtime") {
-		if strings.Contains(mbeanName, "Uptime") {
-			return "jvm_runtime_uptime_seconds"
-		}
-		if strings.Contains(mbeanName, "StartTime") {
-			return "jvm_runtime_start_time_seconds"
-		}
-	}
-
-	// 线程指标
-	if strings.Contains(mbeanName, "Threading") {
-		if strings.Contains(mbeanName, "TotalStartedThreadCount") {
-			return "jvm_threading_total_started_threads"
-		}
-		if strings.Contains(mbeanName, "PeakThreadCount") {
-			return "jvm_threading_peak_thread_count"
-		}
-		if strings.Contains(mbeanName, "DaemonThreadCount") {
-			return "jvm_threading_daemon_thread_count"
-		}
-		// 其他线程指标...
-	}
-
-	return ""
+// jvmPoolName 从 MBean 名称的 name 属性中提取标签值
+// 例如 "java.lang:name=G1 Eden Space,type=MemoryPool" 为 "g1_eden_space"，
+// "java.lang:name=CodeHeap 'non-nmethods',type=MemoryPool" 为 "codeheap_non_nmethods"
+func jvmPoolName(mbeanName string) string {
+	return normalizeLabelValue(mbeanProperty(mbeanName, "name"))
 }
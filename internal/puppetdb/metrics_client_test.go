@@ -0,0 +1,300 @@
+package puppetdb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackend 记录读取请求并返回固定数据的指标 API
+type fakeBackend struct {
+	mbeans   []string
+	data     map[string]map[string]interface{}
+	requests [][]ReadRequest
+}
+
+func (fb *fakeBackend) Name() string {
+	return "v2"
+}
+
+func (fb *fakeBackend) List() ([]string, error) {
+	return fb.mbeans, nil
+}
+
+func (fb *fakeBackend) Read(requests ...ReadRequest) (map[string]map[string]interface{}, error) {
+	fb.requests = append(fb.requests, requests)
+	data := make(map[string]map[string]interface{})
+	for _, request := range requests {
+		for mbean, attributes := range fb.data {
+			if mbean == request.MBean || (isMBeanPattern(request.MBean) && matchMBeanPattern(request.MBean, mbean)) {
+				data[mbean] = attributes
+			}
+		}
+	}
+	return data, nil
+}
+
+func (fb *fakeBackend) Exec(mbean string, operation string, arguments ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// newFakeMetricsClient 创建使用 fakeBackend 的指标客户端，跳过指标 API 检测
+func newFakeMetricsClient(backend *fakeBackend) *MetricsClient {
+	return &MetricsClient{backend: backend, backendCheckedAt: time.Now()}
+}
+
+func TestParseCommandMBean(t *testing.T) {
+	tests := []struct {
+		mbean           string
+		expectedCommand string
+		expectedVersion string
+		expectedMetric  string
+		expectedOK      bool
+	}{
+		{mbean: "puppetlabs.puppetdb.mq:name=replace catalog.9.processing-time", expectedCommand: "replace catalog", expectedVersion: "9", expectedMetric: "processing-time", expectedOK: true},
+		{mbean: "puppetlabs.puppetdb.mq:name=store report.8.depth", expectedCommand: "store report", expectedVersion: "8", expectedMetric: "depth", expectedOK: true},
+		// 命令名称中的 . 属于命令
+		{mbean: "puppetlabs.puppetdb.mq:name=configure.expiration.1.processed", expectedCommand: "configure.expiration", expectedVersion: "1", expectedMetric: "processed", expectedOK: true},
+		{mbean: "puppetlabs.puppetdb.mq:name=global.depth"},
+		{mbean: "puppetlabs.puppetdb.mq:name=global.processing-time"},
+		{mbean: "puppetlabs.puppetdb.mq:name=replace catalog.v9.depth"},
+		{mbean: "puppetlabs.puppetdb.mq:name=.9.depth"},
+		{mbean: "puppetlabs.puppetdb.dlo:name=replace catalog.9.depth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mbean, func(t *testing.T) {
+			command, version, metric, ok := parseCommandMBean(tt.mbean)
+			assert.Equal(t, tt.expectedOK, ok)
+			if tt.expectedOK {
+				assert.Equal(t, tt.expectedCommand, command)
+				assert.Equal(t, tt.expectedVersion, version)
+				assert.Equal(t, tt.expectedMetric, metric)
+			}
+		})
+	}
+}
+
+func TestGetPerCommandMetrics(t *testing.T) {
+	backend := &fakeBackend{
+		mbeans: []string{
+			"puppetlabs.puppetdb.mq:name=global.depth",
+			"puppetlabs.puppetdb.mq:name=global.processed",
+			"puppetlabs.puppetdb.mq:name=replace catalog.9.depth",
+			"puppetlabs.puppetdb.mq:name=replace catalog.9.processing-time",
+			"puppetlabs.puppetdb.mq:name=replace catalog.9.size",
+			"puppetlabs.puppetdb.population:name=num-nodes",
+		},
+		data: map[string]map[string]interface{}{
+			"puppetlabs.puppetdb.mq:name=global.depth":                      {"Count": float64(7)},
+			"puppetlabs.puppetdb.mq:name=replace catalog.9.depth":           {"Count": float64(3)},
+			"puppetlabs.puppetdb.mq:name=replace catalog.9.processing-time": {"Count": float64(10), "Mean": 0.5},
+		},
+	}
+	mc := newFakeMetricsClient(backend)
+
+	commands, err := mc.ReadSnapshot().GetPerCommandMetrics()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []CommandMBean{
+		{Command: "replace catalog", Version: "9", Metric: "depth", Data: map[string]interface{}{"Count": float64(3)}},
+		{Command: "replace catalog", Version: "9", Metric: "processing-time", Data: map[string]interface{}{"Count": float64(10), "Mean": 0.5}},
+	}, commands)
+
+	// 命令列表来自 MBean 列表，只读取导出的指标，不使用通配符
+	var mbeans []string
+	for _, requests := range backend.requests {
+		for _, request := range requests {
+			if strings.HasPrefix(request.MBean, "puppetlabs.puppetdb.mq:") {
+				mbeans = append(mbeans, request.MBean)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"puppetlabs.puppetdb.mq:name=replace catalog.9.depth",
+		"puppetlabs.puppetdb.mq:name=replace catalog.9.processing-time",
+	}, mbeans)
+}
+
+func TestReadSnapshot(t *testing.T) {
+	backend := &fakeBackend{
+		mbeans: []string{"puppetlabs.puppetdb.mq:name=replace catalog.9.depth"},
+		data: map[string]map[string]interface{}{
+			"puppetlabs.puppetdb.database:name=PDBReadPool.pool.ActiveConnections": {"Value": float64(3)},
+			"puppetlabs.puppetdb.database:name=PDBReadPool.pool.Usage":             {"Count": float64(10), "Mean": float64(2)},
+			"puppetlabs.puppetdb.population:name=num-nodes":                        {"Value": float64(12)},
+			"java.lang:type=Threading":                                             {"ThreadCount": float64(40), "PeakThreadCount": float64(50)},
+		},
+	}
+	mc := newFakeMetricsClient(backend)
+
+	snapshot := mc.ReadSnapshot("java.lang:type=Threading")
+
+	// 全部指标组只发送一次批量读取，每个 MBean 只请求一次
+	if !assert.Len(t, backend.requests, 1) {
+		return
+	}
+	requested := make(map[string]ReadRequest)
+	for _, request := range backend.requests[0] {
+		_, duplicate := requested[request.MBean]
+		assert.False(t, duplicate, request.MBean)
+		requested[request.MBean] = request
+	}
+	assert.Contains(t, requested, "puppetlabs.puppetdb.database:name=*")
+	assert.Contains(t, requested, "puppetlabs.puppetdb.mq:name=replace catalog.9.depth")
+	// 规则需要 Threading 的全部属性，合并后的请求不再限定属性
+	assert.Empty(t, requested["java.lang:type=Threading"].Attribute)
+
+	dbMetrics, err := snapshot.GetDBMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]float64{"PDBReadPool": {"ActiveConnections": 3}}, dbMetrics)
+
+	dbPoolStats, err := snapshot.GetDBPoolStats()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]map[string]interface{}{
+		"PDBReadPool": {"Usage": {"Count": float64(10), "Mean": float64(2)}},
+	}, dbPoolStats)
+
+	population, err := snapshot.GetPopulationMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"nodes": 12}, population)
+
+	jvm, err := snapshot.GetJVMMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"threads_active": 40}, jvm)
+
+	mbeans, err := snapshot.GetMBeans()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"java.lang:type=Threading": {"ThreadCount": float64(40), "PeakThreadCount": float64(50)},
+	}, mbeans)
+
+	// 不再发送其他读取请求
+	assert.Len(t, backend.requests, 1)
+}
+
+func TestMetricsSnapshotResult(t *testing.T) {
+	snapshot := &MetricsSnapshot{
+		requests: map[string][]ReadRequest{
+			"database": {NewReadRequest("puppetlabs.puppetdb.database:name=*")},
+			"jvm":      jvmReadRequests,
+		},
+		data: map[string]map[string]interface{}{},
+		err: &ReadError{Failed: map[string]string{
+			"java.lang:type=Memory": "status 404: not found",
+		}},
+	}
+
+	// 部分读取失败只归属到包含失败 MBean 的指标组
+	data, err := snapshot.result("database")
+	assert.NotNil(t, data)
+	assert.NoError(t, err)
+
+	data, err = snapshot.result("jvm")
+	assert.NotNil(t, data)
+	assert.Equal(t, &ReadError{Failed: map[string]string{"java.lang:type=Memory": "status 404: not found"}}, err)
+
+	// 整个批量读取失败时所有指标组都返回该错误
+	snapshot.data, snapshot.err = nil, errors.New("connection refused")
+	data, err = snapshot.result("database")
+	assert.Nil(t, data)
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestReadPlan(t *testing.T) {
+	plan := newReadPlan()
+	plan.add(
+		NewReadRequest("java.lang:type=Memory", "HeapMemoryUsage"),
+		NewReadRequest("java.lang:type=Threading", "ThreadCount"),
+		NewReadRequest("java.lang:type=Memory", "NonHeapMemoryUsage", "HeapMemoryUsage"),
+		NewReadRequest("java.lang:type=Threading"),
+		NewReadRequest("java.lang:type=Threading", "PeakThreadCount"),
+	)
+
+	assert.Equal(t, []ReadRequest{
+		NewReadRequest("java.lang:type=Memory", "HeapMemoryUsage", "NonHeapMemoryUsage"),
+		NewReadRequest("java.lang:type=Threading"),
+	}, plan.requests)
+}
+
+func TestTemplateHTTPEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		expected string
+	}{
+		{endpoint: "/pdb/query/v4/nodes", expected: "/pdb/query/v4/nodes"},
+		{endpoint: "/pdb/query/v4/nodes/web1.example.com", expected: "/pdb/query/v4/nodes/{certname}"},
+		{endpoint: "/pdb/query/v4/nodes/web1/facts", expected: "/pdb/query/v4/nodes/{certname}/facts"},
+		{endpoint: "/pdb/query/v4/nodes/web1/facts/os", expected: "/pdb/query/v4/nodes/{certname}/facts/{fact}"},
+		{endpoint: "/pdb/query/v4/facts/os/Linux", expected: "/pdb/query/v4/facts/{fact}/{value}"},
+		{endpoint: "/pdb/query/v4/resources/File/etc", expected: "/pdb/query/v4/resources/{type}/{title}"},
+		{endpoint: "/pdb/query/v4/environments/production/facts", expected: "/pdb/query/v4/environments/{environment}/facts"},
+		{endpoint: "/pdb/query/v4/reports/0123456789abcdef0123456789abcdef01234567/metrics", expected: "/pdb/query/v4/reports/{hash}/metrics"},
+		// 不在路径参数集合之后的十六进制哈希同样模板化
+		{endpoint: "/pdb/ext/v1/0123456789abcdef0123456789abcdef", expected: "/pdb/ext/v1/{hash}"},
+		{endpoint: "/pdb/ext/v1/0123456789abcdef", expected: "/pdb/ext/v1/0123456789abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			assert.Equal(t, tt.expected, templateHTTPEndpoint(tt.endpoint))
+		})
+	}
+}
+
+func TestMergeDropwizard(t *testing.T) {
+	first := map[string]interface{}{
+		"Count": float64(10), "Mean": float64(1), "Min": float64(0.5), "Max": float64(2), "StdDev": float64(0.3),
+		"50thPercentile": float64(1), "99thPercentile": float64(2), "OneMinuteRate": float64(1),
+		"DurationUnit": "milliseconds", "RateUnit": "events/second",
+	}
+	second := map[string]interface{}{
+		"Count": float64(30), "Mean": float64(3), "Min": float64(1), "Max": float64(9), "StdDev": float64(2),
+		"50thPercentile": float64(3), "99thPercentile": float64(8), "OneMinuteRate": float64(2), "FiveMinuteRate": float64(4),
+		"DurationUnit": "milliseconds", "RateUnit": "events/second",
+	}
+
+	// 只有一个端点时保留完整数据
+	assert.Equal(t, first, mergeDropwizard(nil, first))
+
+	// 合并后不输出分位数、Min、Max 和 StdDev，总和 Mean * Count 等于各端点总和之和
+	merged := mergeDropwizard(mergeDropwizard(nil, first), second)
+	assert.Equal(t, map[string]interface{}{
+		"Count": float64(40), "Mean": float64(2.5), "OneMinuteRate": float64(3), "FiveMinuteRate": float64(4),
+		"DurationUnit": "milliseconds", "RateUnit": "events/second",
+	}, merged)
+	assert.Equal(t, float64(10*1+30*3), merged["Mean"].(float64)*merged["Count"].(float64))
+
+	// 计数为 0 的计时器合并后平均值为 0
+	assert.Equal(t, map[string]interface{}{"Count": float64(0), "Mean": float64(0)}, mergeDropwizard(
+		map[string]interface{}{"Count": float64(0), "Mean": float64(0)},
+		map[string]interface{}{"Count": float64(0), "Mean": float64(0)},
+	))
+}
+
+func TestGetHTTPMetrics(t *testing.T) {
+	backend := &fakeBackend{
+		data: map[string]map[string]interface{}{
+			"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes.service-time":               {"Count": float64(5), "Mean": float64(2), "99thPercentile": float64(4)},
+			"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes/web1/facts.service-time":    {"Count": float64(1), "Mean": float64(1), "99thPercentile": float64(1)},
+			"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes/web2/facts.service-time":    {"Count": float64(3), "Mean": float64(3), "99thPercentile": float64(5)},
+			"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes/web2/facts.200":             {"Count": float64(3)},
+			"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes/web2/facts.other":           {"Count": float64(1)},
+			"puppetlabs.puppetdb.storage:name=/pdb/query/v4/nodes/web2/facts.service-time": {"Count": float64(9)},
+		},
+	}
+
+	metrics, err := newFakeMetricsClient(backend).ReadSnapshot().GetHTTPMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]map[string]interface{}{
+		"/pdb/query/v4/nodes": {
+			"service-time": {"Count": float64(5), "Mean": float64(2), "99thPercentile": float64(4)},
+		},
+		"/pdb/query/v4/nodes/{certname}/facts": {
+			"service-time": {"Count": float64(4), "Mean": float64(2.5)},
+			"200":          {"Count": float64(3)},
+		},
+	}, metrics)
+}
@@ -0,0 +1,146 @@
+package puppetdb
+
+// MetricsSnapshot 一次抓取中全部指标 MBean 的读取结果
+// 各组指标的读取请求合并为一次批量读取（每个通配符只读取一次），各 Get* 方法从中解析自己的指标
+type MetricsSnapshot struct {
+	mc *MetricsClient
+
+	// requests 按指标组记录的读取请求，用于将部分读取失败归属到对应的指标组
+	requests map[string][]ReadRequest
+	// listErr 获取 MBean 列表的错误，按命令的消息队列指标依赖 MBean 列表
+	listErr error
+	data    map[string]map[string]interface{}
+	err     error
+}
+
+// snapshotGroup 一组指标的读取请求
+type snapshotGroup struct {
+	name     string
+	requests []ReadRequest
+}
+
+// ReadSnapshot 在一次批量读取中读取全部内置指标和 mbeans 中的 MBean（完整属性）
+// 按命令的消息队列指标和可选的 Jetty 组件依赖 MBean 列表（缓存 5 分钟）
+func (mc *MetricsClient) ReadSnapshot(mbeans ...string) *MetricsSnapshot {
+	s := &MetricsSnapshot{mc: mc, requests: make(map[string][]ReadRequest)}
+
+	available, err := mc.cachedMBeans()
+	s.listErr = err
+
+	extra := make([]ReadRequest, len(mbeans))
+	for i, mbean := range mbeans {
+		extra[i] = NewReadRequest(mbean)
+	}
+
+	groups := []snapshotGroup{
+		{"population", []ReadRequest{NewReadRequest(populationPrefix + "*")}},
+		{"storage", []ReadRequest{NewReadRequest(storagePrefix + "*")}},
+		{"commands", commandReadRequests(available)},
+		{"admin", []ReadRequest{NewReadRequest(adminPrefix + "*")}},
+		{"dlo", []ReadRequest{NewReadRequest(dloPrefix + "*")}},
+		{"database", []ReadRequest{NewReadRequest(databasePrefix + "*")}},
+		{"jvm", jvmReadRequests},
+		{"jvm_comprehensive", jvmAttributeReadRequests()},
+		{"jvm_pools", jvmPoolReadRequests},
+		{"jetty", jettyReadRequests(available)},
+		{"http", []ReadRequest{NewReadRequest(httpPrefix + "*")}},
+		{"mbeans", extra},
+	}
+
+	plan := newReadPlan()
+	for _, group := range groups {
+		s.requests[group.name] = group.requests
+		plan.add(group.requests...)
+	}
+
+	s.data, s.err = mc.Read(plan.requests...)
+	return s
+}
+
+// result 返回指定指标组的读取结果：整个批量读取失败时数据为 nil，
+// 部分 MBean 读取失败时错误只包含这组请求中失败的 MBean
+func (s *MetricsSnapshot) result(group string) (map[string]map[string]interface{}, error) {
+	if s.data == nil {
+		return nil, s.err
+	}
+	readErr, ok := s.err.(*ReadError)
+	if !ok {
+		return s.data, s.err
+	}
+
+	failed := make(map[string]string)
+	for mbean, message := range readErr.Failed {
+		for _, request := range s.requests[group] {
+			if mbean == request.MBean || (isMBeanPattern(request.MBean) && matchMBeanPattern(request.MBean, mbean)) {
+				failed[mbean] = message
+				break
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return s.data, &ReadError{Failed: failed}
+	}
+	return s.data, nil
+}
+
+// GetMBeans 返回 ReadSnapshot 中额外指定的 MBean 的完整数据，按 MBean 名称返回
+// 部分 MBean 读取失败时返回已读取的数据和 *ReadError
+func (s *MetricsSnapshot) GetMBeans() (map[string]map[string]interface{}, error) {
+	data, err := s.result("mbeans")
+	if data == nil {
+		return nil, err
+	}
+
+	mbeans := make(map[string]map[string]interface{})
+	for _, request := range s.requests["mbeans"] {
+		if attributes, ok := data[request.MBean]; ok {
+			mbeans[request.MBean] = attributes
+		}
+	}
+	return mbeans, err
+}
+
+// readPlan 一次批量读取的请求，同一 MBean 的多个请求合并为一个
+// Jolokia 按 MBean 名称返回数据，同一 MBean 的多个请求会互相覆盖，因此合并它们的属性
+type readPlan struct {
+	requests []ReadRequest
+	index    map[string]int
+}
+
+func newReadPlan() *readPlan {
+	return &readPlan{index: make(map[string]int)}
+}
+
+// add 添加读取请求，任一请求读取全部属性时合并后的请求读取全部属性
+func (p *readPlan) add(requests ...ReadRequest) {
+	for _, request := range requests {
+		i, ok := p.index[request.MBean]
+		if !ok {
+			request.Attribute = append([]string(nil), request.Attribute...)
+			p.index[request.MBean] = len(p.requests)
+			p.requests = append(p.requests, request)
+			continue
+		}
+
+		merged := &p.requests[i]
+		if len(merged.Attribute) == 0 || len(request.Attribute) == 0 {
+			*merged = NewReadRequest(request.MBean)
+			continue
+		}
+		for _, attribute := range request.Attribute {
+			if !containsString(merged.Attribute, attribute) {
+				merged.Attribute = append(merged.Attribute, attribute)
+			}
+		}
+	}
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
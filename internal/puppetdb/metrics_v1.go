@@ -0,0 +1,163 @@
+package puppetdb
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// legacyBackend 通过 /metrics/v1/mbeans 访问 MBean，用于未启用 Jolokia 的 PuppetDB
+// 每个 MBean 单独请求，模式读取根据 MBean 列表在本地展开，不支持调用操作
+type legacyBackend struct {
+	*PuppetDB
+
+	// list 返回（缓存的）MBean 列表，用于展开模式读取
+	list func() ([]string, error)
+}
+
+// Name 实现 MetricsBackend
+func (lb *legacyBackend) Name() string {
+	return "v1"
+}
+
+// List 实现 MetricsBackend，/metrics/v1/mbeans 返回 MBean 名称到其路径的映射
+func (lb *legacyBackend) List() ([]string, error) {
+	var result map[string]interface{}
+	if err := lb.get("/metrics/v1/mbeans", "", &result); err != nil {
+		return nil, err
+	}
+
+	mbeans := make([]string, 0, len(result))
+	for mbean := range result {
+		mbeans = append(mbeans, mbean)
+	}
+	return mbeans, nil
+}
+
+// Read 实现 MetricsBackend
+func (lb *legacyBackend) Read(requests ...ReadRequest) (map[string]map[string]interface{}, error) {
+	data := make(map[string]map[string]interface{})
+	failed := make(map[string]string)
+	var lastErr error
+
+	for _, request := range requests {
+		mbeans := []string{request.MBean}
+		if isMBeanPattern(request.MBean) {
+			all, err := lb.list()
+			if err != nil {
+				failed[request.MBean] = err.Error()
+				lastErr = err
+				continue
+			}
+			mbeans = mbeans[:0]
+			for _, mbean := range all {
+				if matchMBeanPattern(request.MBean, mbean) {
+					mbeans = append(mbeans, mbean)
+				}
+			}
+		}
+
+		for _, mbean := range mbeans {
+			var attributes map[string]interface{}
+			if err := lb.get("/metrics/v1/mbeans/"+url.PathEscape(mbean), "", &attributes); err != nil {
+				failed[mbean] = err.Error()
+				lastErr = err
+				continue
+			}
+			if len(request.Attribute) > 0 {
+				// 与 Jolokia 的 ignoreErrors 一致，忽略不存在的属性
+				selected := make(map[string]interface{}, len(request.Attribute))
+				for _, attribute := range request.Attribute {
+					if value, ok := attributes[attribute]; ok {
+						selected[attribute] = value
+					}
+				}
+				attributes = selected
+			}
+			addReadData(data, mbean, attributes)
+		}
+	}
+
+	if len(data) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	if len(failed) > 0 {
+		return data, &ReadError{Failed: failed}
+	}
+	return data, nil
+}
+
+// Exec 实现 MetricsBackend，/metrics/v1 不支持调用 MBean 操作
+func (lb *legacyBackend) Exec(mbean string, operation string, arguments ...interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("failed to exec %s on %s: operations are not supported by /metrics/v1", operation, mbean)
+}
+
+// matchMBeanPattern 判断 MBean 名称是否匹配 JMX ObjectName 模式
+// 域和属性值支持 * 和 ? 通配符（可匹配包括 / 在内的任意字符），属性列表末尾的 * 表示允许其他属性
+func matchMBeanPattern(pattern string, mbean string) bool {
+	i := strings.Index(pattern, ":")
+	j := strings.Index(mbean, ":")
+	if i < 0 || j < 0 {
+		return false
+	}
+	if !matchGlob(pattern[:i], mbean[:j]) {
+		return false
+	}
+
+	properties := make(map[string]string)
+	for _, property := range strings.Split(mbean[j+1:], ",") {
+		if k := strings.Index(property, "="); k >= 0 {
+			properties[property[:k]] = property[k+1:]
+		}
+	}
+
+	matched := 0
+	open := false
+	for _, property := range strings.Split(pattern[i+1:], ",") {
+		if property == "*" {
+			open = true
+			continue
+		}
+		k := strings.Index(property, "=")
+		if k < 0 {
+			return false
+		}
+		value, ok := properties[property[:k]]
+		if !ok {
+			return false
+		}
+		if !matchGlob(property[k+1:], value) {
+			return false
+		}
+		matched++
+	}
+	return open || matched == len(properties)
+}
+
+// matchGlob 按 JMX ObjectName 的规则匹配通配符：* 匹配任意长度的字符，? 匹配单个字符
+// 与 path.Match 不同，通配符也匹配 /，HTTP 端点 MBean 的名称（例如 /pdb/query/v4/nodes.200）依赖这一点
+func matchGlob(pattern string, name string) bool {
+	p, n := []rune(pattern), []rune(name)
+	// star 最近一个 * 在模式中的位置，next 该 * 之后回溯时名称的起始位置
+	star, next := -1, 0
+	i, j := 0, 0
+	for j < len(n) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, next = i, j
+			i++
+		case i < len(p) && (p[i] == '?' || p[i] == n[j]):
+			i++
+			j++
+		case star >= 0:
+			next++
+			i, j = star+1, next
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
@@ -0,0 +1,35 @@
+package puppetdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchMBeanPatternHTTP(t *testing.T) {
+	const pattern = "puppetlabs.puppetdb.http:name=*"
+
+	// HTTP 端点 MBean 的名称包含 /，* 需要匹配 /（名称取自 mbean.txt）
+	for _, mbean := range []string{
+		"puppetlabs.puppetdb.http:name=/pdb",
+		"puppetlabs.puppetdb.http:name=/pdb.200",
+		"puppetlabs.puppetdb.http:name=/pdb/cmd/v1.service-time",
+		"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes.service-time",
+		"puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes/qdpuppet-web2.200",
+		"puppetlabs.puppetdb.http:name=/pdb/query/v4/reports/fc5008534d49edbd163b8b2e99d8566147a47736/metrics.service-time",
+	} {
+		assert.True(t, matchMBeanPattern(pattern, mbean), mbean)
+	}
+
+	assert.False(t, matchMBeanPattern(pattern, "puppetlabs.puppetdb.mq:name=global.processed"))
+	assert.False(t, matchMBeanPattern(pattern, "puppetlabs.puppetdb.http:name=/pdb,type=other"))
+}
+
+func TestMatchMBeanPattern(t *testing.T) {
+	assert.True(t, matchMBeanPattern("puppetlabs.puppetdb.http:name=/pdb/query/v4/*.service-time", "puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes/web1.service-time"))
+	assert.False(t, matchMBeanPattern("puppetlabs.puppetdb.http:name=/pdb/query/v4/*.service-time", "puppetlabs.puppetdb.http:name=/pdb/query/v4/nodes.200"))
+	assert.True(t, matchMBeanPattern("puppetlabs.puppetdb.*:name=global.?rocessed", "puppetlabs.puppetdb.mq:name=global.processed"))
+	assert.True(t, matchMBeanPattern("java.lang:type=GarbageCollector,*", "java.lang:type=GarbageCollector,name=G1 Young Generation"))
+	assert.False(t, matchMBeanPattern("java.lang:type=GarbageCollector", "java.lang:type=GarbageCollector,name=G1 Young Generation"))
+	assert.True(t, matchMBeanPattern("a:name=*b", "a:name=*ab"))
+}
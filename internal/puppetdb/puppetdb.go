@@ -1,6 +1,7 @@
 package puppetdb
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -9,13 +10,17 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // PuppetDB stores informations used to connect to a PuppetDB
 type PuppetDB struct {
 	options *Options
 	client  *http.Client
+	token   string
+	health  apiHealth
 }
 
 // Options contains the options used to connect to a PuppetDB
@@ -25,6 +30,8 @@ type Options struct {
 	CACertPath string
 	KeyPath    string
 	SSLVerify  bool
+	// TokenPath is a file holding an RBAC token sent in the X-Authentication header (optional)
+	TokenPath string
 }
 
 // Node is a structure returned by a PuppetDB
@@ -44,6 +51,25 @@ type Node struct {
 	FactsTimestamp          string `json:"facts_timestamp"`
 }
 
+// Report is a subset of the fields of a report returned by a PuppetDB
+type Report struct {
+	Certname    string `json:"certname"`
+	Hash        string `json:"hash"`
+	Status      string `json:"status"`
+	Environment string `json:"environment"`
+	EndTime     string `json:"end_time"`
+	ReceiveTime string `json:"receive_time"`
+	Noop        bool   `json:"noop"`
+}
+
+// Fact is a structure returned by a PuppetDB
+type Fact struct {
+	Certname    string      `json:"certname"`
+	Name        string      `json:"name"`
+	Value       interface{} `json:"value"`
+	Environment string      `json:"environment"`
+}
+
 // ReportMetric is a structure returned by a PuppetDB
 type ReportMetric struct {
 	Name     string  `json:"name"`
@@ -67,37 +93,52 @@ func NewClient(options *Options) (p *PuppetDB, err error) {
 	}
 
 	if puppetdbURL.Scheme == "https" {
-		// Load client cert
-		cert, err := tls.LoadX509KeyPair(options.CertPath, options.KeyPath)
-		if err != nil {
-			err = fmt.Errorf("failed to load keypair: %s", err)
-			return nil, err
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: !options.SSLVerify,
 		}
 
-		// Load CA cert
-		caCert, err := os.ReadFile(options.CACertPath)
-		if err != nil {
-			err = fmt.Errorf("failed to load ca certificate: %s", err)
-			return nil, err
+		// Load client cert, optional when authenticating with a token
+		if options.CertPath != "" || options.KeyPath != "" {
+			cert, err := tls.LoadX509KeyPair(options.CertPath, options.KeyPath)
+			if err != nil {
+				err = fmt.Errorf("failed to load keypair: %s", err)
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
 		}
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
 
-		// Setup HTTPS client
-		tlsConfig := &tls.Config{
-			Certificates:       []tls.Certificate{cert},
-			RootCAs:            caCertPool,
-			InsecureSkipVerify: !options.SSLVerify,
+		// Load CA cert, the system roots are used when none is given
+		if options.CACertPath != "" {
+			caCert, err := os.ReadFile(options.CACertPath)
+			if err != nil {
+				err = fmt.Errorf("failed to load ca certificate: %s", err)
+				return nil, err
+			}
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+			tlsConfig.RootCAs = caCertPool
 		}
+
 		// BuildNameToCertificate is deprecated; leave nil to let Go select the first compatible certificate.
 		transport = &http.Transport{TLSClientConfig: tlsConfig}
 	} else {
 		transport = &http.Transport{}
 	}
 
+	var token string
+	if options.TokenPath != "" {
+		content, err := os.ReadFile(options.TokenPath)
+		if err != nil {
+			err = fmt.Errorf("failed to load token: %s", err)
+			return nil, err
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
 	p = &PuppetDB{
 		client:  &http.Client{Transport: transport},
 		options: options,
+		token:   token,
 	}
 	return
 }
@@ -123,6 +164,78 @@ func (p *PuppetDB) ReportMetrics(reportHash string) (reportMetrics []ReportMetri
 	return
 }
 
+// reportsPageSize is the number of reports fetched per request by ReportsSince
+const reportsPageSize = 5000
+
+// ReportsSince returns the reports received by PuppetDB after the given time.
+// Reports are fetched in pages ordered by receive_time, so that a long window
+// on a large fleet doesn't load every report in a single response.
+func (p *PuppetDB) ReportsSince(since time.Time) (reports []Report, err error) {
+	query := fmt.Sprintf(
+		"[\"extract\", [\"certname\", \"hash\", \"status\", \"environment\", \"end_time\", \"receive_time\", \"noop\"], [\">\", \"receive_time\", \"%s\"]]",
+		since.UTC().Format(time.RFC3339),
+	)
+	for offset := 0; ; offset += reportsPageSize {
+		params := url.Values{}
+		params.Set("query", query)
+		params.Set("order_by", "[{\"field\": \"receive_time\", \"order\": \"asc\"}]")
+		params.Set("limit", strconv.Itoa(reportsPageSize))
+		params.Set("offset", strconv.Itoa(offset))
+
+		var page []Report
+		err = p.getParams("/pdb/query/v4/reports", params, &page)
+		if err != nil {
+			err = fmt.Errorf("failed to get reports: %s", err)
+			return
+		}
+		reports = append(reports, page...)
+		if len(page) < reportsPageSize {
+			return
+		}
+	}
+}
+
+// Facts returns the value of the given fact for every node
+func (p *PuppetDB) Facts(name string) (facts []Fact, err error) {
+	err = p.get("/pdb/query/v4/facts", fmt.Sprintf("[\"=\", \"name\", %q]", name), &facts)
+	if err != nil {
+		err = fmt.Errorf("failed to get facts: %s", err)
+		return
+	}
+	return
+}
+
+// Version returns the PuppetDB version from /pdb/meta/v1/version
+func (p *PuppetDB) Version() (version string, err error) {
+	var resp struct {
+		Version string `json:"version"`
+	}
+	err = p.get("/pdb/meta/v1/version", "", &resp)
+	if err != nil {
+		err = fmt.Errorf("failed to get version: %s", err)
+		return
+	}
+	return resp.Version, nil
+}
+
+// ServerTime returns the current time of the PuppetDB server from /pdb/meta/v1/server-time
+func (p *PuppetDB) ServerTime() (serverTime time.Time, err error) {
+	var resp struct {
+		ServerTime string `json:"server_time"`
+	}
+	err = p.get("/pdb/meta/v1/server-time", "", &resp)
+	if err != nil {
+		err = fmt.Errorf("failed to get server time: %s", err)
+		return
+	}
+	serverTime, err = time.Parse(time.RFC3339, resp.ServerTime)
+	if err != nil {
+		err = fmt.Errorf("failed to parse server time: %s", err)
+		return
+	}
+	return
+}
+
 // GetRaw performs a GET against the given endpoint and returns the raw response body.
 // Endpoint should be a path like "/status/v1/services" or "/metrics/v2/list".
 func (p *PuppetDB) GetRaw(endpoint string, query string) (body []byte, err error) {
@@ -141,7 +254,7 @@ func (p *PuppetDB) GetRaw(endpoint string, query string) (body []byte, err error
 		err = fmt.Errorf("failed to build request: %s", err)
 		return
 	}
-	resp, err := p.client.Do(req)
+	resp, err := p.do(req)
 	if err != nil {
 		err = fmt.Errorf("failed to call API: %s", err)
 		return
@@ -193,6 +306,22 @@ func (p *PuppetDB) MetricsV2() (MetricsV2Response, error) {
 }
 
 func (p *PuppetDB) get(endpoint string, query string, object interface{}) (err error) {
+	params := url.Values{}
+	if query != "" {
+		params.Set("query", query)
+	}
+	return p.getParams(endpoint, params, object)
+}
+
+// getParams performs a GET against the given endpoint with the given URL
+// parameters and unmarshals the JSON response into object
+func (p *PuppetDB) getParams(endpoint string, params url.Values, object interface{}) (err error) {
+	return p.getParamsContext(context.Background(), endpoint, params, object)
+}
+
+// getParamsContext is getParams with a context bounding the whole request,
+// including reading the response body
+func (p *PuppetDB) getParamsContext(ctx context.Context, endpoint string, params url.Values, object interface{}) (err error) {
 	// Build URL by appending the provided endpoint to the base URL.
 	// The caller should pass endpoint paths such as:
 	//   "/status/v1/services"
@@ -208,15 +337,15 @@ func (p *PuppetDB) get(endpoint string, query string, object interface{}) (err e
 	} else {
 		myurl = fmt.Sprintf("%s/%s", base, endpoint)
 	}
-	if query != "" {
-		myurl = fmt.Sprintf("%s?query=%s", myurl, url.QueryEscape(query))
+	if len(params) > 0 {
+		myurl = fmt.Sprintf("%s?%s", myurl, params.Encode())
 	}
-	req, err := http.NewRequest("GET", myurl, strings.NewReader(""))
+	req, err := http.NewRequestWithContext(ctx, "GET", myurl, strings.NewReader(""))
 	if err != nil {
 		err = fmt.Errorf("failed to build request: %s", err)
 		return
 	}
-	resp, err := p.client.Do(req)
+	resp, err := p.do(req)
 	if err != nil {
 		err = fmt.Errorf("failed to call API: %s", err)
 		return
@@ -253,7 +382,7 @@ func (p *PuppetDB) Post(endpoint string, contentType string, body []byte) (resp
 	}
 
 	req.Header.Set("Content-Type", contentType)
-	resp, err = p.client.Do(req)
+	resp, err = p.do(req)
 	if err != nil {
 		err = fmt.Errorf("failed to call POST API: %s", err)
 		return
@@ -273,7 +402,17 @@ type ServiceInfo struct {
 
 // Services returns a typed map of services from /status/v1/services
 func (p *PuppetDB) Services() (map[string]ServiceInfo, error) {
-	body, err := p.GetRaw("/status/v1/services", "")
+	return p.ServicesAtLevel("")
+}
+
+// ServicesAtLevel returns the services from /status/v1/services at the given
+// detail level (critical, info or debug). An empty level uses the server default.
+func (p *PuppetDB) ServicesAtLevel(level string) (map[string]ServiceInfo, error) {
+	endpoint := "/status/v1/services"
+	if level != "" {
+		endpoint = fmt.Sprintf("%s?level=%s", endpoint, url.QueryEscape(level))
+	}
+	body, err := p.GetRaw(endpoint, "")
 	if err != nil {
 		return nil, err
 	}
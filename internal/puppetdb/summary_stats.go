@@ -0,0 +1,209 @@
+package puppetdb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SummaryStats /pdb/admin/v1/summary-stats 中与数据库增长相关的统计
+// NodeResources 和 NodeEdges 以分位数（0-1）为键
+type SummaryStats struct {
+	TableRows     map[string]float64
+	TableDeadRows map[string]float64
+	TableSizes    map[string]float64
+	FactPaths     map[string]float64
+	NodeResources map[float64]float64
+	NodeEdges     map[float64]float64
+}
+
+// summaryQuantiles 由逐节点计数计算的分位数
+var summaryQuantiles = []float64{0, 0.25, 0.5, 0.75, 1}
+
+// sizeUnits pg_size_pretty 输出的单位
+var sizeUnits = map[string]float64{
+	"bytes": 1,
+	"kb":    1 << 10,
+	"mb":    1 << 20,
+	"gb":    1 << 30,
+	"tb":    1 << 40,
+	"pb":    1 << 50,
+}
+
+// SummaryStats 获取 PuppetDB 的汇总统计，该查询会扫描整个数据库，应以较低频率调用
+// ctx 取消或超时时中止请求
+func (p *PuppetDB) SummaryStats(ctx context.Context) (stats *SummaryStats, err error) {
+	var raw map[string]interface{}
+	err = p.getParamsContext(ctx, "/pdb/admin/v1/summary-stats", nil, &raw)
+	if err != nil {
+		err = fmt.Errorf("failed to get summary stats: %s", err)
+		return
+	}
+	return parseSummaryStats(raw), nil
+}
+
+// parseSummaryStats 解析汇总统计中的表使用情况、表大小、事实路径和逐节点统计
+func parseSummaryStats(raw map[string]interface{}) *SummaryStats {
+	stats := &SummaryStats{
+		TableRows:     make(map[string]float64),
+		TableDeadRows: make(map[string]float64),
+		TableSizes:    make(map[string]float64),
+		FactPaths:     make(map[string]float64),
+	}
+
+	for _, row := range summaryRows(raw["table_usage"]) {
+		table, _ := row["relname"].(string)
+		if table == "" {
+			continue
+		}
+		if rows, ok := summaryNumber(row["n_live_tup"]); ok {
+			stats.TableRows[table] = rows
+		}
+		if rows, ok := summaryNumber(row["n_dead_tup"]); ok {
+			stats.TableDeadRows[table] = rows
+		}
+	}
+
+	for _, row := range summaryRows(raw["relation_sizes"]) {
+		table := summaryString(row, "relation", "relname", "table")
+		if table == "" {
+			continue
+		}
+		for _, key := range []string{"total_size", "size", "pg_total_relation_size"} {
+			if size, ok := parseSizePretty(row[key]); ok {
+				stats.TableSizes[table] = size
+				break
+			}
+		}
+	}
+
+	for _, row := range summaryRows(raw["fact_path_counts_by_depth"]) {
+		depth, ok := summaryNumber(row["depth"])
+		if !ok {
+			continue
+		}
+		if count, ok := summaryNumber(row["count"]); ok {
+			stats.FactPaths[strconv.Itoa(int(depth))] = count
+		}
+	}
+
+	stats.NodeResources = summaryDistribution(summaryRows(raw["num_resources_per_node"]))
+	stats.NodeEdges = summaryDistribution(summaryRows(raw["num_edges_per_node"]))
+
+	return stats
+}
+
+// summaryDistribution 将逐节点统计转换为分位数
+// PuppetDB 可能直接返回 percentile_cont 数组（从最小值到最大值均匀分布），也可能返回每个节点一行的计数
+func summaryDistribution(rows []map[string]interface{}) map[float64]float64 {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if len(rows) == 1 {
+		for _, value := range rows[0] {
+			percentiles, ok := value.([]interface{})
+			if !ok || len(percentiles) == 0 {
+				continue
+			}
+			result := make(map[float64]float64, len(percentiles))
+			for i, percentile := range percentiles {
+				v, ok := summaryNumber(percentile)
+				if !ok {
+					continue
+				}
+				quantile := 1.0
+				if len(percentiles) > 1 {
+					quantile = float64(i) / float64(len(percentiles)-1)
+				}
+				result[quantile] = v
+			}
+			return result
+		}
+	}
+
+	counts := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		if count, ok := summaryNumber(row["count"]); ok {
+			counts = append(counts, count)
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	sort.Float64s(counts)
+
+	result := make(map[float64]float64, len(summaryQuantiles))
+	for _, quantile := range summaryQuantiles {
+		// 线性插值，与 PostgreSQL 的 percentile_cont 一致
+		pos := quantile * float64(len(counts)-1)
+		lower := int(math.Floor(pos))
+		upper := int(math.Ceil(pos))
+		result[quantile] = counts[lower] + (counts[upper]-counts[lower])*(pos-float64(lower))
+	}
+	return result
+}
+
+// summaryRows 将查询结果转换为行列表，忽略不是对象的行
+func summaryRows(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	rows := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if row, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// summaryString 返回行中第一个非空的字符串字段
+func summaryString(row map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := row[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// summaryNumber 解析数字字段，PostgreSQL 的 bigint 可能以字符串返回
+func summaryNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// parseSizePretty 解析字节数或 pg_size_pretty 的输出（例如 "8192 bytes"、"16 kB"、"1234 MB"）
+func parseSizePretty(value interface{}) (float64, bool) {
+	if size, ok := value.(float64); ok {
+		return size, true
+	}
+	s, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, false
+	}
+	size, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	if len(fields) == 1 {
+		return size, true
+	}
+	unit, ok := sizeUnits[strings.ToLower(fields[1])]
+	if !ok {
+		return 0, false
+	}
+	return size * unit, true
+}
@@ -0,0 +1,113 @@
+package puppetdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizePretty(t *testing.T) {
+	tests := []struct {
+		value      interface{}
+		expected   float64
+		expectedOK bool
+	}{
+		{value: float64(8192), expected: 8192, expectedOK: true},
+		{value: "8192 bytes", expected: 8192, expectedOK: true},
+		{value: "16 kB", expected: 16 << 10, expectedOK: true},
+		{value: "1234 MB", expected: 1234 << 20, expectedOK: true},
+		{value: "2 GB", expected: 2 << 30, expectedOK: true},
+		{value: "1.5 TB", expected: 1.5 * (1 << 40), expectedOK: true},
+		{value: "4096", expected: 4096, expectedOK: true},
+		{value: "12 parsecs"},
+		{value: "many bytes"},
+		{value: "1 2 MB"},
+		{value: ""},
+		{value: true},
+		{value: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.value), func(t *testing.T) {
+			size, ok := parseSizePretty(tt.value)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func TestParseSummaryStats(t *testing.T) {
+	var raw map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"table_usage": [
+			{"relname": "catalogs", "n_live_tup": 120, "n_dead_tup": "15"},
+			{"relname": "", "n_live_tup": 1},
+			"not a row"
+		],
+		"relation_sizes": [
+			{"relation": "catalogs", "total_size": "16 kB"},
+			{"relname": "reports", "size": 4096},
+			{"table": "facts", "pg_total_relation_size": "2 MB"},
+			{"relation": "unknown", "total_size": "lots"}
+		],
+		"fact_path_counts_by_depth": [
+			{"depth": 0, "count": 40},
+			{"depth": "1", "count": "300"},
+			{"count": 5}
+		],
+		"num_resources_per_node": [
+			{"certname": "a", "count": 10},
+			{"certname": "b", "count": 30},
+			{"certname": "c", "count": 20}
+		],
+		"num_edges_per_node": [
+			{"percentile_cont": [1, 5, 9]}
+		]
+	}`), &raw))
+
+	stats := parseSummaryStats(raw)
+	assert.Equal(t, map[string]float64{"catalogs": 120}, stats.TableRows)
+	assert.Equal(t, map[string]float64{"catalogs": 15}, stats.TableDeadRows)
+	assert.Equal(t, map[string]float64{"catalogs": 16 << 10, "reports": 4096, "facts": 2 << 20}, stats.TableSizes)
+	assert.Equal(t, map[string]float64{"0": 40, "1": 300}, stats.FactPaths)
+	// 逐节点计数按线性插值计算分位数
+	assert.Equal(t, map[float64]float64{0: 10, 0.25: 15, 0.5: 20, 0.75: 25, 1: 30}, stats.NodeResources)
+	// percentile_cont 数组从最小值到最大值均匀分布
+	assert.Equal(t, map[float64]float64{0: 1, 0.5: 5, 1: 9}, stats.NodeEdges)
+}
+
+func TestParseSummaryStatsEmpty(t *testing.T) {
+	stats := parseSummaryStats(map[string]interface{}{})
+	assert.Empty(t, stats.TableRows)
+	assert.Empty(t, stats.TableSizes)
+	assert.Empty(t, stats.FactPaths)
+	assert.Nil(t, stats.NodeResources)
+	assert.Nil(t, stats.NodeEdges)
+}
+
+func TestSummaryStatsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	pdb, err := NewClient(&Options{URL: server.URL})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// 请求超时后立即返回，不等待服务端完成查询
+	start := time.Now()
+	_, err = pdb.SummaryStats(ctx)
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
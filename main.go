@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,18 +19,53 @@ import (
 
 // Config stores handler's configuration
 type Config struct {
-	Version        bool   `long:"version" description:"Show version."`
-	PuppetDBUrl    string `short:"u" long:"puppetdb-url" description:"PuppetDB base URL (e.g. https://puppetdb:8081)." env:"PUPPETDB_URL" required:"true" default:"https://puppetdb:8081"`
-	CertFile       string `long:"cert-file" description:"A PEM encoded certificate file." env:"PUPPETDB_CERT_FILE"`
-	KeyFile        string `long:"key-file" description:"A PEM encoded private key file." env:"PUPPETDB_KEY_FILE"`
-	CACertFile     string `long:"ca-file" description:"A PEM encoded CA's certificate." env:"PUPPETDB_CA_FILE"`
-	SSLSkipVerify  bool   `long:"ssl-skip-verify" description:"Skip SSL verification." env:"PUPPETDB_SSL_SKIP_VERIFY"`
-	ScrapeInterval string `long:"scrape-interval" description:"Duration between two scrapes." env:"PUPPETDB_SCRAPE_INTERVAL" default:"5s"`
-	ListenAddress  string `long:"listen-address" description:"Address to listen on for web interface and telemetry." env:"PUPPETDB_LISTEN_ADDRESS" default:"0.0.0.0:9635"`
-	MetricPath     string `long:"metric-path" description:"Path under which to expose metrics." env:"PUPPETDB_METRIC_PATH" default:"/metrics"`
-	Verbose        bool   `long:"verbose" description:"Enable debug mode" env:"PUPPETDB_VERBOSE"`
-	UnreportedNode string `long:"unreported-node" description:"Tag nodes as unreported if the latest report is older than the defined duration." env:"PUPPETDB_UNREPORTED_NODE" default:"2h"`
-	Categories     string `long:"categories" description:"Report metrics categories to scrape." env:"REPORT_METRICS_CATEGORIES" default:"resources,time,changes,events"`
+	Version                 bool     `long:"version" description:"Show version."`
+	PuppetDBUrl             string   `short:"u" long:"puppetdb-url" description:"PuppetDB base URL (e.g. https://puppetdb:8081)." env:"PUPPETDB_URL" required:"true" default:"https://puppetdb:8081"`
+	Instances               string   `long:"instances" description:"JSON file listing the PuppetDB instances to monitor; every metric gets a puppetdb label with the instance name." env:"PUPPETDB_INSTANCES"`
+	CertFile                string   `long:"cert-file" description:"A PEM encoded certificate file." env:"PUPPETDB_CERT_FILE"`
+	KeyFile                 string   `long:"key-file" description:"A PEM encoded private key file." env:"PUPPETDB_KEY_FILE"`
+	CACertFile              string   `long:"ca-file" description:"A PEM encoded CA's certificate." env:"PUPPETDB_CA_FILE"`
+	SSLSkipVerify           bool     `long:"ssl-skip-verify" description:"Skip SSL verification." env:"PUPPETDB_SSL_SKIP_VERIFY"`
+	TokenFile               string   `long:"token-file" description:"File holding an RBAC token sent in the X-Authentication header." env:"PUPPETDB_TOKEN_FILE"`
+	StatusURL               string   `long:"status-url" description:"Base URL of the status API (/status/v1), defaults to the PuppetDB URL." env:"PUPPETDB_STATUS_URL"`
+	StatusCertFile          string   `long:"status-cert-file" description:"A PEM encoded certificate file for the status API, defaults to --cert-file." env:"PUPPETDB_STATUS_CERT_FILE"`
+	StatusKeyFile           string   `long:"status-key-file" description:"A PEM encoded private key file for the status API, defaults to --key-file." env:"PUPPETDB_STATUS_KEY_FILE"`
+	StatusCACertFile        string   `long:"status-ca-file" description:"A PEM encoded CA's certificate for the status API, defaults to --ca-file." env:"PUPPETDB_STATUS_CA_FILE"`
+	StatusTokenFile         string   `long:"status-token-file" description:"RBAC token file for the status API, defaults to --token-file." env:"PUPPETDB_STATUS_TOKEN_FILE"`
+	MetricsURL              string   `long:"metrics-url" description:"Base URL of the metrics API (/metrics/v1, /metrics/v2), defaults to the PuppetDB URL (e.g. http://localhost:8080)." env:"PUPPETDB_METRICS_URL"`
+	MetricsCertFile         string   `long:"metrics-cert-file" description:"A PEM encoded certificate file for the metrics API, defaults to --cert-file." env:"PUPPETDB_METRICS_CERT_FILE"`
+	MetricsKeyFile          string   `long:"metrics-key-file" description:"A PEM encoded private key file for the metrics API, defaults to --key-file." env:"PUPPETDB_METRICS_KEY_FILE"`
+	MetricsCACertFile       string   `long:"metrics-ca-file" description:"A PEM encoded CA's certificate for the metrics API, defaults to --ca-file." env:"PUPPETDB_METRICS_CA_FILE"`
+	MetricsTokenFile        string   `long:"metrics-token-file" description:"RBAC token file for the metrics API, defaults to --token-file." env:"PUPPETDB_METRICS_TOKEN_FILE"`
+	ScrapeInterval          string   `long:"scrape-interval" description:"Duration between two scrapes." env:"PUPPETDB_SCRAPE_INTERVAL" default:"5s"`
+	ListenAddress           string   `long:"listen-address" description:"Address to listen on for web interface and telemetry." env:"PUPPETDB_LISTEN_ADDRESS" default:"0.0.0.0:9635"`
+	MetricPath              string   `long:"metric-path" description:"Path under which to expose metrics." env:"PUPPETDB_METRIC_PATH" default:"/metrics"`
+	Verbose                 bool     `long:"verbose" description:"Enable debug mode" env:"PUPPETDB_VERBOSE"`
+	UnreportedNode          string   `long:"unreported-node" description:"Tag nodes as unreported if the latest report is older than the defined duration." env:"PUPPETDB_UNREPORTED_NODE" default:"2h"`
+	Categories              string   `long:"categories" description:"Report metrics categories to scrape." env:"REPORT_METRICS_CATEGORIES" default:"resources,time,changes,events"`
+	ReportHistory           string   `long:"report-history-window" description:"Window of report history used to detect consecutive failures and flapping (0 to disable)." env:"PUPPETDB_REPORT_HISTORY_WINDOW" default:"24h"`
+	RunIntervalFact         string   `long:"run-interval-fact" description:"Fact holding each node's Puppet run interval (seconds or duration, e.g. puppet_runinterval)." env:"PUPPETDB_RUN_INTERVAL_FACT"`
+	RunIntervalEnvironments string   `long:"run-interval-environments" description:"Per-environment run intervals (e.g. production=30m,appliances=24h)." env:"PUPPETDB_RUN_INTERVAL_ENVIRONMENTS"`
+	InferRunInterval        bool     `long:"infer-run-interval" description:"Infer each node's run interval from its report history." env:"PUPPETDB_INFER_RUN_INTERVAL"`
+	UnreportedMissedRuns    int      `long:"unreported-missed-runs" description:"Tag nodes with a known run interval as unreported after this many missed runs." env:"PUPPETDB_UNREPORTED_MISSED_RUNS" default:"4"`
+	MaintenanceFile         string   `long:"maintenance-file" description:"JSON file of maintenance windows, reloaded when it changes." env:"PUPPETDB_MAINTENANCE_FILE"`
+	MaintenanceFact         string   `long:"maintenance-fact" description:"Boolean fact marking nodes as in maintenance (e.g. maintenance_mode)." env:"PUPPETDB_MAINTENANCE_FACT"`
+	MaintenanceAPI          bool     `long:"maintenance-api" description:"Expose an HTTP API to register maintenance windows under /api/v1/maintenance (/api/v1/maintenance/<instance> with --instances)." env:"PUPPETDB_MAINTENANCE_API"`
+	MaintenanceListen       string   `long:"maintenance-listen-address" description:"Address to serve the maintenance API on, separate from the metrics listener since the API is unauthenticated." env:"PUPPETDB_MAINTENANCE_LISTEN_ADDRESS" default:"127.0.0.1:9636"`
+	NodePurgeTTL            string   `long:"node-purge-ttl" description:"PuppetDB node-purge-ttl, used to report inactive nodes approaching purge (0 to disable)." env:"PUPPETDB_NODE_PURGE_TTL" default:"336h"`
+	NodePurgeWarning        string   `long:"node-purge-warning" description:"Count inactive nodes as approaching purge when they will be purged within this duration." env:"PUPPETDB_NODE_PURGE_WARNING" default:"24h"`
+	Inventory               string   `long:"inventory" description:"Expected-node inventory to reconcile against PuppetDB: a CSV/JSON file (reloaded on change) or an HTTP URL." env:"PUPPETDB_INVENTORY"`
+	InventoryRefresh        string   `long:"inventory-refresh" description:"Duration between two fetches of an HTTP inventory." env:"PUPPETDB_INVENTORY_REFRESH" default:"5m"`
+	CAURL                   string   `long:"ca-url" description:"Puppet Server CA base URL (e.g. https://puppet:8140), queried with the PuppetDB TLS settings." env:"PUPPETDB_CA_URL"`
+	CARefresh               string   `long:"ca-refresh" description:"Duration between two fetches of the Puppet CA certificate statuses." env:"PUPPETDB_CA_REFRESH" default:"5m"`
+	PuppetServerURLs        []string `long:"puppetserver-url" description:"Puppet Server base URL to collect /status/v1/services from (repeatable, e.g. https://puppet:8140)." env:"PUPPETSERVER_URLS" env-delim:","`
+	StatusLevel             string   `long:"status-level" description:"Detail level requested from /status/v1/services." env:"PUPPETDB_STATUS_LEVEL" default:"info" choice:"critical" choice:"info" choice:"debug"`
+	MBeanRules              string   `long:"mbean-rules" description:"JSON file with rules mapping MBeans from /metrics/v2/list to metrics." env:"PUPPETDB_MBEAN_RULES"`
+	MBeanRulesPrefix        string   `long:"mbean-rules-prefix" description:"Prefix added to the names of metrics generated by MBean rules." env:"PUPPETDB_MBEAN_RULES_PREFIX" default:"puppetdb_jmx_"`
+	SummaryStatsInterval    string   `long:"summary-stats-interval" description:"Duration between two fetches of the PuppetDB admin summary stats (0 to disable)." env:"PUPPETDB_SUMMARY_STATS_INTERVAL" default:"1h"`
+	SummaryStatsTimeout     string   `long:"summary-stats-timeout" description:"Timeout of a PuppetDB admin summary stats request (0 for no timeout)." env:"PUPPETDB_SUMMARY_STATS_TIMEOUT" default:"5m"`
+	JVMThreadInspection     bool     `long:"jvm-thread-inspection" description:"Count JVM threads by state and detect deadlocks with Jolokia exec operations (requires a Jolokia policy allowing exec on java.lang:type=Threading)." env:"PUPPETDB_JVM_THREAD_INSPECTION"`
+	HealthWeights           string   `long:"health-weights" description:"Health score penalties per node problem (e.g. failed=1,unreported=1,noop_pending=0.25,cached_catalog=0.5,corrective_changes=0.25)." env:"PUPPETDB_HEALTH_WEIGHTS"`
 }
 
 var (
@@ -76,12 +113,127 @@ func main() {
 	for _, category := range cats {
 		categories[category] = struct{}{}
 	}
-	exp, err := exporter.NewPuppetDBExporter(c.PuppetDBUrl, c.CertFile, c.CACertFile, c.KeyFile, c.SSLSkipVerify, categories)
+	unreportedNode, err := time.ParseDuration(c.UnreportedNode)
 	if err != nil {
-		log.Fatalf("failed to initialize exporter: %s", err)
+		log.Fatalf("failed to parse unreported duration: %s", err)
 	}
 
-	go exp.Scrape(interval, c.UnreportedNode, categories)
+	reportHistoryWindow, err := time.ParseDuration(c.ReportHistory)
+	if err != nil {
+		log.Fatalf("failed to parse report history window: %s", err)
+	}
+
+	runIntervalEnvironments, err := parseDurationMap(c.RunIntervalEnvironments)
+	if err != nil {
+		log.Fatalf("failed to parse run interval environments: %s", err)
+	}
+
+	nodePurgeTTL, err := time.ParseDuration(c.NodePurgeTTL)
+	if err != nil {
+		log.Fatalf("failed to parse node purge ttl: %s", err)
+	}
+
+	nodePurgeWarning, err := time.ParseDuration(c.NodePurgeWarning)
+	if err != nil {
+		log.Fatalf("failed to parse node purge warning: %s", err)
+	}
+
+	inventoryRefresh, err := time.ParseDuration(c.InventoryRefresh)
+	if err != nil {
+		log.Fatalf("failed to parse inventory refresh duration: %s", err)
+	}
+
+	caRefresh, err := time.ParseDuration(c.CARefresh)
+	if err != nil {
+		log.Fatalf("failed to parse CA refresh duration: %s", err)
+	}
+
+	summaryStatsInterval, err := time.ParseDuration(c.SummaryStatsInterval)
+	if err != nil {
+		log.Fatalf("failed to parse summary stats interval: %s", err)
+	}
+
+	summaryStatsTimeout, err := time.ParseDuration(c.SummaryStatsTimeout)
+	if err != nil {
+		log.Fatalf("failed to parse summary stats timeout: %s", err)
+	}
+
+	healthWeights, err := parseFloatMap(c.HealthWeights)
+	if err != nil {
+		log.Fatalf("failed to parse health weights: %s", err)
+	}
+
+	statusAPI := exporter.APIEndpoint{
+		URL:        c.StatusURL,
+		CertPath:   c.StatusCertFile,
+		KeyPath:    c.StatusKeyFile,
+		CACertPath: c.StatusCACertFile,
+		TokenPath:  c.StatusTokenFile,
+	}
+	metricsAPI := exporter.APIEndpoint{
+		URL:        c.MetricsURL,
+		CertPath:   c.MetricsCertFile,
+		KeyPath:    c.MetricsKeyFile,
+		CACertPath: c.MetricsCACertFile,
+		TokenPath:  c.MetricsTokenFile,
+	}
+
+	options := exporter.Options{
+		URL:                     c.PuppetDBUrl,
+		CertPath:                c.CertFile,
+		CACertPath:              c.CACertFile,
+		KeyPath:                 c.KeyFile,
+		SSLSkipVerify:           c.SSLSkipVerify,
+		TokenPath:               c.TokenFile,
+		StatusAPI:               statusAPI,
+		MetricsAPI:              metricsAPI,
+		Categories:              categories,
+		UnreportedNode:          unreportedNode,
+		ReportHistoryWindow:     reportHistoryWindow,
+		RunIntervalFact:         c.RunIntervalFact,
+		RunIntervalEnvironments: runIntervalEnvironments,
+		InferRunInterval:        c.InferRunInterval,
+		UnreportedMissedRuns:    c.UnreportedMissedRuns,
+		MaintenanceFile:         c.MaintenanceFile,
+		MaintenanceFact:         c.MaintenanceFact,
+		HealthWeights:           healthWeights,
+		NodePurgeTTL:            nodePurgeTTL,
+		NodePurgeWarning:        nodePurgeWarning,
+		Inventory:               c.Inventory,
+		InventoryRefresh:        inventoryRefresh,
+		CAURL:                   c.CAURL,
+		CARefresh:               caRefresh,
+		PuppetServerURLs:        c.PuppetServerURLs,
+		StatusLevel:             c.StatusLevel,
+		MBeanRulesFile:          c.MBeanRules,
+		MBeanRulesPrefix:        c.MBeanRulesPrefix,
+		JVMThreadInspection:     c.JVMThreadInspection,
+		SummaryStatsInterval:    summaryStatsInterval,
+		SummaryStatsTimeout:     summaryStatsTimeout,
+	}
+
+	instances := []*exporter.Options{&options}
+	if c.Instances != "" {
+		instances, err = exporter.LoadInstances(c.Instances, options)
+		if err != nil {
+			log.Fatalf("failed to load instances: %s", err)
+		}
+		if c.CAURL != "" || len(c.PuppetServerURLs) > 0 || c.Inventory != "" {
+			log.Warnf("--ca-url, --puppetserver-url and --inventory are ignored with --instances, set ca_url, puppetserver_urls and inventory per instance")
+		}
+	}
+
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer}
+	exporters := make([]*exporter.Exporter, 0, len(instances))
+	for _, instance := range instances {
+		exp, err := exporter.NewPuppetDBExporter(instance)
+		if err != nil {
+			log.Fatalf("failed to initialize exporter %s: %s", instance.Name, err)
+		}
+		exporters = append(exporters, exp)
+		gatherers = append(gatherers, exp.Gatherer())
+		go scrapeForever(exp, interval)
+	}
 
 	buildInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "puppetdb_exporter_build_info",
@@ -90,7 +242,27 @@ func main() {
 	buildInfo.WithLabelValues(version, commitSha1, buildDate, runtime.Version()).Set(1)
 	prometheus.MustRegister(buildInfo)
 
-	http.Handle(c.MetricPath, promhttp.Handler())
+	// Keep serving the other instances when one of them fails to gather
+	http.Handle(c.MetricPath, promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{
+		ErrorLog:      log.StandardLogger(),
+		ErrorHandling: promhttp.ContinueOnError,
+	}))
+	if c.MaintenanceAPI {
+		// The maintenance API has no authentication, so it is never served
+		// on the metrics listener
+		mux := http.NewServeMux()
+		for _, exp := range exporters {
+			if exp.Name() == "" {
+				mux.Handle("/api/v1/maintenance", exp.MaintenanceHandler())
+			} else {
+				mux.Handle("/api/v1/maintenance/"+exp.Name(), exp.MaintenanceHandler())
+			}
+		}
+		go func() {
+			log.Infof("Providing maintenance API at %s/api/v1/maintenance", c.MaintenanceListen)
+			log.Fatal(http.ListenAndServe(c.MaintenanceListen, mux))
+		}()
+	}
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`
 <html>
@@ -106,3 +278,61 @@ func main() {
 	log.Infof("Providing metrics at %s%s", c.ListenAddress, c.MetricPath)
 	log.Fatal(http.ListenAndServe(c.ListenAddress, nil))
 }
+
+// scrapeForever runs the scrape loop of an instance and restarts it after a
+// panic, so that a failing instance doesn't stop the others.
+func scrapeForever(exp *exporter.Exporter, interval time.Duration) {
+	for {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("scrape loop of instance %q panicked: %v", exp.Name(), r)
+				}
+			}()
+			exp.Scrape(interval)
+		}()
+		time.Sleep(interval)
+	}
+}
+
+// parseDurationMap parses a comma separated list of key=duration pairs.
+func parseDurationMap(s string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid pair %q, expected key=duration", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		result[strings.TrimSpace(kv[0])] = d
+	}
+	return result, nil
+}
+
+// parseFloatMap parses a comma separated list of key=number pairs.
+func parseFloatMap(s string) (map[string]float64, error) {
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid pair %q, expected key=number", pair)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil {
+			return nil, err
+		}
+		result[strings.TrimSpace(kv[0])] = f
+	}
+	return result, nil
+}